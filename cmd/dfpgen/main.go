@@ -0,0 +1,169 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Command dfpgen generates a decimal floating-point package
+// for a chosen storage size and exponent/mantissa split.
+//
+// The generated package has the same API as dfp.Value. It imports dfp to share dfp.RoundingMode
+// and dfp.JSONMode, and to convert values to and from dfp.Value. It contains
+// value.go, strconv.go and value_test.go files. For example, dfp32 is generated by
+//
+//	go run ./cmd/dfpgen -pkg dfp32 -bits 32 -exp 6 -out dfp/dfp32
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	minExpBits  = 4
+	maxExpBits  = 16
+	minMantBits = 20
+	maxMantBits = 60
+)
+
+// layout describes a generated value type.
+type layout struct {
+	Package string
+	Bits    int
+	ExpBits int
+}
+
+// MantBits returns the number of bits used for mantissa.
+func (l layout) MantBits() int {
+	return l.Bits - l.ExpBits
+}
+
+// Bias returns the exponent bias.
+func (l layout) Bias() int {
+	return 1<<uint(l.ExpBits-1) - 1
+}
+
+// MaxExponent returns the maximum exponent.
+func (l layout) MaxExponent() int {
+	return 1 << uint(l.ExpBits-1)
+}
+
+// MinExponent returns the minimum exponent.
+func (l layout) MinExponent() int {
+	return -l.Bias()
+}
+
+// MaxMantissa returns the maximum mantissa.
+func (l layout) MaxMantissa() uint64 {
+	return 1<<uint(l.MantBits()) - 1
+}
+
+// Digits returns the number of digits in the maximum mantissa.
+func (l layout) Digits() int {
+	return len(strconv.FormatUint(l.MaxMantissa(), 10))
+}
+
+// Precision returns the number of decimal digits, that can always be represented.
+func (l layout) Precision() int {
+	return l.Digits() - 1
+}
+
+// Diagram returns the lines of a bit diagram for the doc comment of the value type.
+func (l layout) Diagram() []string {
+	positions := []byte(strings.Repeat(" ", l.Bits))
+	put := func(col, bit int) {
+		copy(positions[col:], strconv.Itoa(bit))
+	}
+	put(0, l.Bits-1)
+	put(l.ExpBits, l.MantBits()-1)
+	put(l.Bits-1, 0)
+	return []string{
+		strings.TrimRight(string(positions), " "),
+		strings.Repeat("_", l.ExpBits) + "|" + strings.Repeat("_", l.MantBits()-1),
+		strings.Repeat("e", l.ExpBits) + strings.Repeat("m", l.MantBits()),
+	}
+}
+
+func (l layout) validate() error {
+	if len(l.Package) == 0 {
+		return errors.New("empty package name")
+	}
+	if l.Bits != 32 && l.Bits != 64 {
+		return fmt.Errorf("unsupported size %d, must be 32 or 64", l.Bits)
+	}
+	if l.ExpBits < minExpBits || l.ExpBits > maxExpBits {
+		return fmt.Errorf("exponent bits must be in [%d, %d], got %d", minExpBits, maxExpBits, l.ExpBits)
+	}
+	if mb := l.MantBits(); mb < minMantBits || mb > maxMantBits {
+		return fmt.Errorf("mantissa bits must be in [%d, %d], got %d", minMantBits, maxMantBits, mb)
+	}
+	return nil
+}
+
+type file struct {
+	name, template string
+}
+
+func generate(l layout, withTests bool) (map[string][]byte, error) {
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+	files := []file{{"value.go", valueTemplate}, {"strconv.go", strconvTemplate}}
+	if withTests {
+		files = append(files, file{"value_test.go", testTemplate})
+	}
+	header := fmt.Sprintf("// Code generated by dfpgen -pkg %s -bits %d -exp %d. DO NOT EDIT.\n\n"+
+		"// Copyright 2020 Aleksandr Demakin. All rights reserved.\n\n", l.Package, l.Bits, l.ExpBits)
+	result := make(map[string][]byte, len(files))
+	for _, f := range files {
+		tmpl, err := template.New(f.name).Delims("[[", "]]").Parse(f.template)
+		if err != nil {
+			return nil, fmt.Errorf("%s: template parsing failed: %v", f.name, err)
+		}
+		buf := bytes.NewBufferString(header)
+		if err := tmpl.Execute(buf, l); err != nil {
+			return nil, fmt.Errorf("%s: template execution failed: %v", f.name, err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: formatting failed: %v", f.name, err)
+		}
+		result[f.name] = src
+	}
+	return result, nil
+}
+
+func run(l layout, out string, withTests bool) error {
+	files, err := generate(l, withTests)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(out, name), src, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	var l layout
+	flag.StringVar(&l.Package, "pkg", "", "package name")
+	flag.IntVar(&l.Bits, "bits", 64, "storage size in bits, 32 or 64")
+	flag.IntVar(&l.ExpBits, "exp", 8, "number of exponent bits")
+	out := flag.String("out", ".", "output directory")
+	withTests := flag.Bool("tests", true, "generate value_test.go")
+	flag.Parse()
+	if err := run(l, *out, *withTests); err != nil {
+		fmt.Fprintln(os.Stderr, "dfpgen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayout(t *testing.T) {
+	a := assert.New(t)
+	l := layout{Package: "dfp32", Bits: 32, ExpBits: 6}
+	a.Equal(26, l.MantBits())
+	a.Equal(31, l.Bias())
+	a.Equal(32, l.MaxExponent())
+	a.Equal(-31, l.MinExponent())
+	a.Equal(uint64(67108863), l.MaxMantissa())
+	a.Equal(8, l.Digits())
+	a.Equal(7, l.Precision())
+	a.Equal([]string{
+		"31    25                       0",
+		"______|_________________________",
+		"eeeeeemmmmmmmmmmmmmmmmmmmmmmmmmm",
+	}, l.Diagram())
+
+	l = layout{Package: "dfp", Bits: 64, ExpBits: 8}
+	a.Equal(uint64(72057594037927935), l.MaxMantissa())
+	a.Equal(16, l.Precision())
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		l   layout
+		err string
+	}{
+		{layout{Package: "p", Bits: 32, ExpBits: 6}, ""},
+		{layout{Package: "p", Bits: 64, ExpBits: 16}, ""},
+		{layout{Bits: 32, ExpBits: 6}, "empty package name"},
+		{layout{Package: "p", Bits: 16, ExpBits: 6}, "unsupported size 16, must be 32 or 64"},
+		{layout{Package: "p", Bits: 32, ExpBits: 3}, "exponent bits must be in [4, 16], got 3"},
+		{layout{Package: "p", Bits: 32, ExpBits: 13}, "mantissa bits must be in [20, 60], got 19"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			_, err := generate(test.l, true)
+			if len(test.err) == 0 {
+				a.NoError(err)
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping generated packages tests in short mode")
+	}
+	a := assert.New(t)
+	for _, l := range []layout{
+		{Package: "narrow", Bits: 32, ExpBits: 4},
+		{Package: "wide", Bits: 32, ExpBits: 12},
+		{Package: "long", Bits: 64, ExpBits: 11},
+	} {
+		t.Run(l.Package, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", l.Package)
+			if !a.NoError(err) {
+				return
+			}
+			defer os.RemoveAll(dir)
+			if !a.NoError(run(l, dir, true)) || !a.NoError(writeModule(dir)) {
+				return
+			}
+			cmd := exec.Command("go", "test", ".")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			a.NoError(err, "%s", out)
+		})
+	}
+}
+
+// writeModule makes dir a module, which imports dfp from this repository.
+func writeModule(dir string) error {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		return err
+	}
+	mod, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return err
+	}
+	sum, err := ioutil.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		return err
+	}
+	// reuse the requirements of this module, so that the generated tests build with the same dependencies.
+	mod = bytes.Replace(mod, []byte("module github.com/avdva/numeric"), []byte("module generated"), 1)
+	mod = append(mod, "\nrequire github.com/avdva/numeric v0.0.0\n\nreplace github.com/avdva/numeric => "...)
+	mod = append(mod, strconv.Quote(root)+"\n"...)
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644)
+}
@@ -0,0 +1,1148 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package main
+
+// valueTemplate is the template of value.go, that contains the value type and its methods.
+// It is executed with a layout, and uses "[[" and "]]" as delimiters.
+const valueTemplate = `// Package [[.Package]] implements a [[.Bits]]-bit decimal floating-point number.
+// It has the same API as dfp.Value, and provides [[.Precision]] digits of precision.
+package [[.Package]]
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+type (
+	number = uint[[.Bits]]
+)
+
+const (
+	bitsInNumber = [[.Bits]]
+	expBits      = [[.ExpBits]]
+	mantBits     = bitsInNumber - expBits
+	bias         = 1<<(expBits-1) - 1
+	// maxExponent is [[.MaxExponent]].
+	maxExponent = 1 << (expBits - 1)
+	// minExponent is [[.MinExponent]].
+	minExponent = -bias
+
+	expMask  = 1<<expBits - 1
+	mantMask = 1<<mantBits - 1
+
+	// maxMantissa is [[.MaxMantissa]].
+	maxMantissa = mantMask
+	minMantissa = 1
+)
+
+var (
+	// decimalFactorTable contains powers of 10 up to 1e19.
+	decimalFactorTable = makeDecimalFactorTable()
+
+	digitsInMaxMantissa = decimalDigits(maxMantissa)
+
+	jsonParts = []string{"{\"m\":", ",\"e\":", "}"}
+	jsonLen   = len(jsonParts[0]) + len(jsonParts[1]) + len(jsonParts[2])
+
+	zero = Value(0)
+)
+
+var (
+	// Max is the maximum possible value.
+	Max = fromMantAndExp(maxMantissa, maxExponent)
+	// Min is the minimum possible value.
+	Min = fromMantAndExp(minMantissa, minExponent)
+)
+
+// Value is a positive decimal floating-point number.
+// It uses a uint[[.Bits]] value as a data type, where
+// [[.ExpBits]] bits are used for exponent and [[.MantBits]] for mantissa.
+//
+[[range .Diagram]]//	[[.]]
+[[end]]//
+// Value can be useful for representing numbers like prices in financial services.
+type Value number
+
+// FromUint64 returns a value for given uint64 number.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromUint64(v uint64) Value {
+	return adjustMantExp(v, 0)
+}
+
+// FromMantAndExp returns a value for given mantissa and exponent.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromMantAndExp(mant uint64, exp int32) Value {
+	return adjustMantExp(mant, int(exp)).Normalized()
+}
+
+// FromValue returns a value for given dfp.Value.
+// Returns false, if v cannot be represented precisely.
+// In this case the least significant digits are truncated, or the result becomes zero or Max.
+func FromValue(v dfp.Value) (Value, bool) {
+	result := FromMantAndExp(v.MantUint64(), v.Exp())
+	m, e := split(result)
+	return result, dfp.FromMantAndExp(m, int32(e)).Eq(v)
+}
+
+// ToValue converts v into a dfp.Value. Returns false, if v cannot be represented precisely.
+// In this case the least significant digits are truncated, or the result becomes zero or dfp.Max.
+func (v Value) ToValue() (dfp.Value, bool) {
+	m, e := split(v)
+	result := dfp.FromMantAndExp(m, int32(e))
+	return result, FromMantAndExp(result.MantUint64(), result.Exp()).Eq(v)
+}
+
+// FromFloat64 returns a value for given float64 value.
+// The result is the shortest decimal, that represents the same float64 number.
+// Returns an error for nagative values, infinities, and not-a-numbers.
+func FromFloat64(v float64) (Value, error) {
+	if v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return zero, fmt.Errorf("bad float number")
+	}
+	return FromString(strconv.FormatFloat(v, 'e', -1, 64))
+}
+
+// MustFromFloat64 returns a value for given float64 value. It panics on an error.
+func MustFromFloat64(f float64) Value {
+	v, err := FromFloat64(f)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FromString parses a string into a value.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromString(s string) (Value, error) {
+	parsed, e, neg, err := parse(s)
+	if neg {
+		return zero, fmt.Errorf("negative value")
+	}
+	if err != nil { // could still be a float
+		if f, fltErr := strconv.ParseFloat(s, 64); fltErr == nil {
+			return FromFloat64(f)
+		}
+		return zero, err
+	}
+	return fromStringAndExp(parsed, e), nil
+}
+
+// MustFromString parses a string into a value. It panics on an error.
+func MustFromString(s string) Value {
+	v, err := FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// fromStringAndExp parses a string without leading and trailing zeros into Value.
+func fromStringAndExp(digits string, e int) Value {
+	if len(digits) == 0 {
+		return zero
+	}
+	if toCut := len(digits) - digitsInMaxMantissa; toCut > 0 {
+		e += toCut
+		digits = digits[:digitsInMaxMantissa]
+	}
+	return adjustMantExp(parseMant(digits), e)
+}
+
+// MarshalJSON marshals value according to current dfp.JSONMode.
+// See dfp.JSONMode and dfp.JSONMode* constants.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return v.toJSON(dfp.JSONMode), nil
+}
+
+func (v Value) toJSON(mode int) []byte {
+	switch mode {
+	case dfp.FormatFloat:
+		return []byte(strconv.FormatFloat(v.Float64(), 'f', -1, 64))
+	case dfp.FormatJSONObject:
+		return []byte(toMEJSON(v))
+	case dfp.JSONModeCompact:
+		if decimalFormatLen(v)+2 <= jsonMEFormatLen(v) { // +2 for a pair of quotes
+			return v.toJSON(dfp.FormatString)
+		}
+		return v.toJSON(dfp.FormatJSONObject)
+	default: // marshal as a string
+		var builder strings.Builder
+		builder.WriteRune('"')
+		m, e := split(v)
+		formatMantExp(m, e, 'f', &builder)
+		builder.WriteRune('"')
+		return []byte(builder.String())
+	}
+}
+
+func toMEJSON(v Value) string {
+	var builder strings.Builder
+	m, e := split(v)
+	builder.WriteString(jsonParts[0])
+	builder.WriteString(strconv.FormatUint(m, 10))
+	builder.WriteString(jsonParts[1])
+	builder.WriteString(strconv.Itoa(e))
+	builder.WriteString(jsonParts[2])
+	return builder.String()
+}
+
+// UnmarshalJSON unmarshals a string, float, or an object into a value.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty json")
+	}
+	switch data[0] {
+	case '{':
+		d := struct {
+			M uint64
+			E int32
+		}{}
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		*v = FromMantAndExp(d.M, d.E)
+	default:
+		value, err := FromString(string(data))
+		if err != nil {
+			return err
+		}
+		*v = value
+	}
+	return nil
+}
+
+// GoString returns debug string representation.
+func (v Value) GoString() string {
+	m, e := split(v)
+	return fmt.Sprintf("{%v, %v}", m, e)
+}
+
+// String returns a string representation of the value.
+func (v Value) String() string {
+	var builder strings.Builder
+	m, e := split(v.Normalized())
+	formatMantExp(m, e, 'f', &builder)
+	return builder.String()
+}
+
+// Format implements fmt.Formatter and allows to format values as a string.
+//
+//	'f', 's' will produce a decimal string, e.g. 123.456
+//	'e', 'v' will produce scientific notation, e.g. 123456e7
+func (v Value) Format(f fmt.State, c rune) {
+	m, e := split(v.Normalized())
+	formatMantExp(m, e, c, f)
+}
+
+// MantUint64 returns v's mantissa as is.
+func (v Value) MantUint64() uint64 {
+	return mant(v)
+}
+
+// Exp returns v's exponent as is.
+func (v Value) Exp() int32 {
+	return int32(exp(v))
+}
+
+// Eq returns true if both values represent the same number.
+func (v Value) Eq(other Value) bool {
+	if v == other {
+		return true
+	}
+	return v.Normalized() == other.Normalized()
+}
+
+// IsZero returns true if the value has zero mantissa.
+func (v Value) IsZero() bool {
+	return mant(v) == 0
+}
+
+// Uint64 returns the integer part of the value as a uint64 number.
+// If it does not fit uint64, math.MaxUint64 is returned.
+func (v Value) Uint64() uint64 {
+	m, e := split(v.Normalized())
+	if e < 0 {
+		if -e >= len(decimalFactorTable) {
+			return 0
+		}
+		return m / pow10(-e)
+	}
+	p := pow10(e)
+	if p == 0 && m > 0 {
+		return math.MaxUint64
+	}
+	hi, lo := bits.Mul64(m, p)
+	if hi > 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
+
+// Float64 returns the nearest float64 value.
+func (v Value) Float64() float64 {
+	m, e := split(v)
+	f, _ := strconv.ParseFloat(strconv.FormatUint(m, 10)+"e"+strconv.Itoa(e), 64)
+	return f
+}
+
+// Normalized eliminates trailing zeros in the mantissa.
+// The process increases the exponent, and stops if it exceeds the maximum possible exponent,
+// so that it is possible, that the mantissa will still have trailing zeros.
+func (v Value) Normalized() Value {
+	m, e := split(v)
+	if m == 0 {
+		return zero
+	}
+	return fromMantAndExp(trimZeros(m, e, maxExponent))
+}
+
+// Cmp compares two values.
+// Returns -1 if a < b, 0 if a == b, 1 if a > b.
+func (v Value) Cmp(other Value) int {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	ediff := e1 - e2
+
+	if ediff == 0 || m1 == 0 || m2 == 0 {
+		return uint64Cmp(m1, m2)
+	}
+
+	// compare highest decimal digit position
+	maxDigit1 := e1 + decimalDigits(m1)
+	maxDigit2 := e2 + decimalDigits(m2)
+	if maxDigit1 > maxDigit2 {
+		return 1
+	} else if maxDigit1 < maxDigit2 {
+		return -1
+	}
+
+	// the mantissa with more digits has at most digitsInMaxMantissa digits, so the product fits uint64.
+	if ediff > 0 {
+		m1 *= pow10(ediff)
+	} else {
+		m2 *= pow10(-ediff)
+	}
+	return uint64Cmp(m1, m2)
+}
+
+// Floor returns the nearest value less than or equal to v that has prec decimal places.
+// Note that prec can be negative.
+func (v Value) Floor(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundDown)
+}
+
+// Round rounds the value to prec decimal places.
+// Note that prec can be negative.
+func (v Value) Round(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundNearest)
+}
+
+// Ceil returns the nearest value greater than or equal to v that has prec decimal places.
+// Note that prec can be negative.
+func (v Value) Ceil(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundUp)
+}
+
+// RoundTo rounds the value to prec decimal places using given rounding mode.
+// Note that prec can be negative.
+func (v Value) RoundTo(prec int, mode dfp.RoundingMode) Value {
+	m, e := split(v)
+	return adjustMantExp(round(m, e, prec, mode))
+}
+
+// Add returns the sum of two values.
+// If the resulting mantissa overflows max mantissa, the least significant digits will be truncated.
+// If the result overflows Max, Max is returned.
+func (v Value) Add(other Value) Value {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	// first, check for obvious cases, when one of the arguments is zero
+	if m1 == 0 {
+		if m2 == 0 {
+			return zero
+		}
+		return other
+	}
+	if m2 == 0 {
+		return v
+	}
+	m1, m2, e := toEqualExp(m1, e1, m2, e2)
+	res := m1 + m2
+	if res > maxMantissa {
+		if e == maxExponent {
+			return Max
+		}
+		res /= 10
+		e++
+	}
+	return fromMantAndExp(res, e)
+}
+
+// Sub returns |a-b| and a boolean flag indicating that the result is negative.
+func (v Value) Sub(other Value) (Value, bool) {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	// first, check for obvious cases, when one of the arguments is zero
+	if m2 == 0 {
+		if m1 == 0 {
+			return zero, false
+		}
+		return v, false
+	}
+	if m1 == 0 {
+		return other, true
+	}
+	m1, m2, e := toEqualExp(m1, e1, m2, e2)
+	if m1 >= m2 {
+		return fromMantAndExp(m1-m2, e), false
+	}
+	return fromMantAndExp(m2-m1, e), true
+}
+
+// Mul returns v * other.
+// If the result underflows Min, zero is returned.
+// If the result overflows Max, Max is returned.
+// If the resulting mantissa overflows max mantissa, the least significant digits will be truncated.
+func (v Value) Mul(other Value) Value {
+	m1, e1 := split(v.Normalized())
+	m2, e2 := split(other.Normalized())
+	// first, check for obvious cases, when one of the arguments is zero
+	if m1 == 0 || m2 == 0 {
+		return zero
+	}
+	// a*10^e1 * b*10^e2 = a * b * 10^(e1+e2)
+	// perform a 128-bit multiplication
+	hi, lo := bits.Mul64(m1, m2)
+	e := e1 + e2
+	if hi > 0 {
+		// the result overflows uint64, so we'll divide it by a factor of 10,
+		// so that it fits a uint64 value again, and add that factor to the resulting exponent.
+		shift := decimalDigits(hi)
+		lo, _ = bits.Div64(hi, lo, pow10(shift))
+		e += shift
+	}
+	return adjustMantExp(lo, e)
+}
+
+// Div calculates a/b. If b == 0, Div panics.
+// The result has up to digitsInMaxMantissa-1 significant digits, the rest of the digits are truncated.
+func (v Value) Div(other Value) Value {
+	return v.Quo(other, -minExponent, dfp.RoundDown).Normalized()
+}
+
+// Quo calculates a/b rounded to prec decimal places using given rounding mode. If b == 0, Quo panics.
+// If the quotient has more digits, than the mantissa can hold, the least significant digits are rounded.
+// Note that prec can be negative.
+func (v Value) Quo(other Value, prec int, mode dfp.RoundingMode) Value {
+	m1, e1 := split(v.Normalized())
+	m2, e2 := split(other.Normalized())
+	if m2 == 0 {
+		panic("division by zero")
+	}
+	if m1 == 0 {
+		return zero
+	}
+	return adjustMantExp(quo(m1, e1, m2, e2, prec, mode))
+}
+
+// quo calculates m1*10^e1 / m2*10^e2 so, that the result has at most prec decimal places,
+// and its mantissa has no more than digitsInMaxMantissa-1 digits.
+func quo(m1 uint64, e1 int, m2 uint64, e2 int, prec int, mode dfp.RoundingMode) (uint64, int) {
+	// m1*10^e1 / m2*10^e2 = (m1*10^shift / m2) * 10^-prec, where shift = e1-e2+prec.
+	shift := e1 - e2 + prec
+	if qDigits := quoDigits(m1, m2) + shift; qDigits > digitsInMaxMantissa-1 {
+		cut := qDigits - (digitsInMaxMantissa - 1)
+		shift -= cut
+		prec -= cut
+	}
+	if -prec < minExponent {
+		shift -= minExponent + prec
+		prec = -minExponent
+	}
+	var q, r, d uint64
+	if shift >= 0 {
+		// the quotient fits uint64, so it is safe to perform a 128-bit multiplication and division.
+		hi, lo := bits.Mul64(m1, pow10(min(shift, len(decimalFactorTable)-1)))
+		if rest := shift - (len(decimalFactorTable) - 1); rest > 0 {
+			var carry uint64
+			carry, lo = bits.Mul64(lo, pow10(rest))
+			hi = hi*pow10(rest) + carry
+		}
+		d = m2
+		q, r = bits.Div64(hi, lo, d)
+	} else {
+		hi, lo := bits.Mul64(m2, pow10(-shift))
+		if hi > 0 || lo == 0 { // the divisor doesn't fit uint64, so the quotient is zero.
+			if mode == dfp.RoundUp {
+				return 1, -prec
+			}
+			return 0, 0
+		}
+		d = lo
+		q, r = m1/d, m1%d
+	}
+	if roundUp(r, d, mode) {
+		q++
+	}
+	return q, -prec
+}
+
+// quoDigits returns the number of decimal digits in the integer part of m1/m2,
+// if m1 and m2 had the same number of digits.
+func quoDigits(m1, m2 uint64) int {
+	d1, d2 := decimalDigits(m1), decimalDigits(m2)
+	if d1 < d2 {
+		m1 *= pow10(d2 - d1)
+	} else {
+		m2 *= pow10(d1 - d2)
+	}
+	if m1 < m2 {
+		return d1 - d2
+	}
+	return d1 - d2 + 1
+}
+
+// round cuts the digits of m*10^e, so that it has at most prec decimal places.
+func round(m uint64, e, prec int, mode dfp.RoundingMode) (uint64, int) {
+	toCut := -prec - e
+	if toCut <= 0 || m == 0 {
+		return m, e
+	}
+	if toCut > decimalDigits(m) { // all the digits are cut, so the value is less than a half.
+		if mode == dfp.RoundUp {
+			return 1, -prec
+		}
+		return 0, 0
+	}
+	d := pow10(toCut)
+	q, r := m/d, m%d
+	if roundUp(r, d, mode) {
+		q++
+	}
+	return q, -prec
+}
+
+// roundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func roundUp(r, d uint64, mode dfp.RoundingMode) bool {
+	switch mode {
+	case dfp.RoundNearest:
+		return r > d-r
+	case dfp.RoundHalfUp:
+		return r != 0 && r >= d-r
+	case dfp.RoundUp:
+		return r != 0
+	default:
+		return false
+	}
+}
+
+// toEqualExp changes m1 and m2 in such a way, that e1 == e2.
+// the result can be used to calculate m1+m2, m1-m2.
+// if the difference between the exponents is too big, m2 can lose some (or all) digits.
+func toEqualExp(m1 uint64, e1 int, m2 uint64, e2 int) (r1, r2 uint64, re int) {
+	if e1 >= e2 {
+		return doToEqualExp(m1, e1, m2, e2)
+	}
+	r1, r2, re = doToEqualExp(m2, e2, m1, e1)
+	return r2, r1, re
+}
+
+// doToEqualExp is a helper for toEqualExp. it assumes that e1 >= e2.
+func doToEqualExp(m1 uint64, e1 int, m2 uint64, e2 int) (r1, r2 uint64, re int) {
+	if e1 == e2 {
+		return m1, m2, e1
+	}
+
+	// try to trim trailing zeros for m2.
+	m2, e2 = trimZeros(m2, e2, e1)
+	if e1 == e2 {
+		return m1, m2, e1
+	}
+
+	// next, try to increase m1 and decrease e1 so, that e1 == e2.
+	toMult := min(decimalDigits(maxMantissa/m1)-1, e1-e2)
+	m1 *= pow10(toMult)
+	e1 -= toMult
+
+	// last resort, decrease m2, lose some digits.
+	if ediff := e1 - e2; ediff > 0 {
+		if p := pow10(ediff); p > 0 {
+			m2 /= p
+		} else {
+			m2 = 0
+		}
+	}
+	return m1, m2, e1
+}
+
+func trimZeros(m uint64, e, eMax int) (uint64, int) {
+	for e < eMax && m%10 == 0 {
+		m /= 10
+		e++
+	}
+	return m, e
+}
+
+func adjustMantExp(m uint64, e int) Value {
+	// fix too large matissa, or too small exponent
+	for (m > maxMantissa || e < minExponent) && m > 0 {
+		m /= 10
+		e++
+	}
+
+	// fix too large exponent
+	for e > maxExponent && m > 0 && m <= maxMantissa/10 {
+		m *= 10
+		e--
+	}
+
+	if m == 0 {
+		return zero
+	}
+	if e > maxExponent {
+		return Max
+	}
+	return fromMantAndExp(m, e)
+}
+
+func jsonMEFormatLen(v Value) int {
+	m, e := split(v)
+	return jsonLen + decimalDigits(m) + len(strconv.Itoa(e))
+}
+
+func decimalFormatLen(v Value) int {
+	m, e := split(v)
+	if m == 0 {
+		return 1
+	}
+	sLen := decimalDigits(m)
+	if e > 0 { // exp trailing zeros
+		sLen += e
+	} else if e < 0 {
+		if diff := sLen + e; diff < 0 { // leading zeros
+			sLen += -diff
+		}
+		sLen++ // a delimeter
+	}
+	return sLen
+}
+
+func makeDecimalFactorTable() (table [20]uint64) {
+	table[0] = 1
+	for i := 1; i < len(table); i++ {
+		table[i] = table[i-1] * 10
+	}
+	return table
+}
+
+// pow10 returns 10^pow, or 0, if it does not fit uint64.
+func pow10(pow int) uint64 {
+	if pow < 0 || pow >= len(decimalFactorTable) {
+		return 0
+	}
+	return decimalFactorTable[pow]
+}
+
+// decimalDigits returns the number of decimal digits in 'value'.
+// see https://graphics.stanford.edu/~seander/bithacks.html#IntegerLog10
+func decimalDigits(value uint64) int {
+	if value == 0 {
+		return 1
+	}
+	d := bits.Len64(value) * 1233 >> 12
+	if value >= decimalFactorTable[d] {
+		d++
+	}
+	return d
+}
+
+func uint64Cmp(a, b uint64) int {
+	switch {
+	case a > b:
+		return 1
+	case a < b:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func exp(v Value) int {
+	return int(v>>mantBits&expMask) - bias
+}
+
+func mant(v Value) uint64 {
+	return uint64(v & mantMask)
+}
+
+func split(v Value) (mantissa uint64, exponent int) {
+	return mant(v), exp(v)
+}
+
+func fromMantAndExp(m uint64, e int) Value {
+	return Value(number(e+bias)<<mantBits | number(m)&mantMask)
+}
+`
+
+// strconvTemplate is the template of strconv.go, that contains parsing and formatting routines.
+const strconvTemplate = `package [[.Package]]
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	delim = '.'
+)
+
+type posError struct {
+	pos int
+	err string
+}
+
+func newPosError(err string, pos int) *posError {
+	return &posError{err: err, pos: pos}
+}
+
+func (pe posError) Error() string {
+	return pe.err + fmt.Sprintf(" at pos %d", pe.pos)
+}
+
+func addPosErrorOffset(err error, offset int) error {
+	var pe *posError
+	if !errors.As(err, &pe) { // try to locate error position.
+		return err
+	}
+	pe.pos += offset
+	return pe
+}
+
+func parse(s string) (digits string, e int, neg bool, err error) {
+	s, offset, neg := prepareString(s)
+	if len(s) == 0 {
+		return "", 0, false, fmt.Errorf("empty input")
+	}
+	digits, e, err = doParse(s)
+	if err != nil {
+		// add what we've trimmed before and add +1 to the offset to start indices from 1.
+		err = fmt.Errorf("parsing failed: %w", addPosErrorOffset(err, offset+1))
+	}
+	return digits, e, neg, err
+}
+
+// doParse parses given decimal string.
+// returns a string without leading and trailing zeros, and an exponent
+func doParse(s string) (result string, e int, err error) {
+	result, delimPos, e, err := removeLeadingZeros(s)
+	if err != nil {
+		return "", 0, err
+	}
+	result, eFromDelim := removeTrailingZerosString(result, delimPos)
+	return result, e + eFromDelim, nil
+}
+
+// prepareString cleans the string from ",-,+ symbols, and spaces.
+func prepareString(s string) (prepared string, offset int, neg bool) {
+	if len(s) > 0 && s[0] == '"' {
+		s = s[1:]
+		offset++
+	}
+	if len(s) > 0 && s[len(s)-1] == '"' {
+		s = s[:len(s)-1]
+	}
+	if trimmed := strings.TrimLeftFunc(s, unicode.IsSpace); len(trimmed) != len(s) {
+		offset += len(s) - len(trimmed)
+		s = trimmed
+	}
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if len(s) == 0 {
+		return "", 0, false
+	}
+	if s[0] == '-' {
+		neg = true
+		offset++
+		s = s[1:]
+	} else if s[0] == '+' {
+		offset++
+		s = s[1:]
+	}
+	return s, offset, neg
+}
+
+func removeLeadingZeros(s string) (result string, delimPos int, e int, err error) {
+	var b strings.Builder
+	delimPos, firstNonZeroPos := -1, -1
+outer:
+	for i, r := range s {
+		switch {
+		case '0' <= r && r <= '9':
+			if b.Len() == 0 {
+				if r == '0' { // trim leading zeros
+					continue
+				}
+				firstNonZeroPos = i
+			}
+			b.WriteRune(r)
+		case r == 'e':
+			parsed, err := strconv.ParseInt(s[i+1:], 10, 32)
+			if err != nil {
+				return "", 0, 0, newPosError("error parsing exponent: "+err.Error(), i+1)
+			}
+			e = int(parsed)
+			break outer
+		case r == delim:
+			if delimPos != -1 {
+				return "", 0, 0, newPosError("unexpected delimeter", i)
+			}
+			delimPos = i
+		default:
+			return "", 0, 0, newPosError(fmt.Sprintf("unexpected symbol %q", r), i)
+		}
+	}
+	if firstNonZeroPos == -1 { // a zero-only string
+		return "", 0, 0, nil
+	}
+
+	result = b.String()
+
+	// move delimPos to the beginning of the trimmed string
+	if delimPos >= 0 {
+		if delimPos < firstNonZeroPos {
+			firstNonZeroPos--
+		}
+		delimPos -= firstNonZeroPos
+	} else { // if there is no delim, add one at the end of the string 123 --> 123.
+		delimPos = len(result)
+	}
+
+	return result, delimPos, e, nil
+}
+
+func removeTrailingZerosString(s string, delimPos int) (result string, e int) {
+	s = strings.TrimRight(s, "0")
+	return s, delimPos - len(s)
+}
+
+// parseMant parses a string of decimal digits, that fits uint64.
+func parseMant(digits string) uint64 {
+	var m uint64
+	for _, r := range digits {
+		m = m*10 + uint64(r-'0')
+	}
+	return m
+}
+
+func formatMantExp(mant uint64, exp int, format rune, w io.Writer) {
+	switch format {
+	case 'f', 's':
+		formatAsDecimal(mant, exp, w)
+	default:
+		formatWithExponent(mant, exp, w)
+	}
+}
+
+func formatAsDecimal(mant uint64, exp int, w io.Writer) {
+	if mant == 0 {
+		io.WriteString(w, "0")
+		return
+	}
+	mString := strconv.FormatUint(mant, 10)
+	switch {
+	case exp >= 0:
+		io.WriteString(w, mString)
+		io.WriteString(w, strings.Repeat("0", exp))
+	default:
+		if diff := len(mString) + exp; diff <= 0 { // add leading zeros and a delimiter
+			io.WriteString(w, "0.")
+			io.WriteString(w, strings.Repeat("0", -diff))
+			io.WriteString(w, mString)
+		} else { // insert a delimeter
+			io.WriteString(w, mString[:diff])
+			io.WriteString(w, ".")
+			io.WriteString(w, mString[diff:])
+		}
+	}
+}
+
+func formatWithExponent(mant uint64, exp int, w io.Writer) {
+	io.WriteString(w, strconv.FormatUint(mant, 10))
+	if mant != 0 {
+		io.WriteString(w, "e"+strconv.Itoa(exp))
+	}
+}
+`
+
+// testTemplate is the template of value_test.go. The tests do not depend on the layout,
+// and check the results against exact math/big calculations.
+const testTemplate = `package [[.Package]]
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/stretchr/testify/assert"
+)
+
+// randomValue returns a value with an exponent in [-randomExpRange, randomExpRange],
+// so that exact calculations stay fast for layouts with wide exponent ranges.
+func randomValue(rnd *rand.Rand) Value {
+	m := uint64(rnd.Int63n(int64(maxMantissa)))
+	m /= pow10(rnd.Intn(digitsInMaxMantissa))
+	lo, hi := max(minExponent, -randomExpRange), min(maxExponent, randomExpRange)
+	return fromMantAndExp(m, rnd.Intn(hi-lo+1)+lo)
+}
+
+const randomExpRange = 64
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func toRat(v Value) *big.Rat {
+	m, e := split(v)
+	return new(big.Rat).Mul(new(big.Rat).SetInt(new(big.Int).SetUint64(m)), pow10Rat(e))
+}
+
+func pow10Rat(e int) *big.Rat {
+	if e < 0 {
+		return new(big.Rat).Inv(pow10Rat(-e))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(e)), nil))
+}
+
+// ulp returns a unit in the last place of a result, that has digitsInMaxMantissa-1 significant digits.
+func ulp(v Value) *big.Rat {
+	m, e := split(v.Normalized())
+	return pow10Rat(e + decimalDigits(m) - (digitsInMaxMantissa - 1))
+}
+
+func TestValueConstants(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(uintptr(bitsInNumber/8), unsafe.Sizeof(Value(0)))
+	a.Equal(uint64(maxMantissa), mant(Max))
+	a.Equal(maxExponent, exp(Max))
+	a.Equal(minExponent, exp(Min))
+	a.Equal(zero, fromMantAndExp(0, minExponent))
+	a.Equal(strconv.FormatUint(maxMantissa, 10)+"e"+strconv.Itoa(maxExponent), fmt.Sprintf("%v", Max))
+	a.Equal("1e"+strconv.Itoa(minExponent), fmt.Sprintf("%v", Min))
+	a.True(pow10(digitsInMaxMantissa-1) <= maxMantissa)
+	a.True(maxMantissa < pow10(digitsInMaxMantissa))
+}
+
+func TestValueParseFormat(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		err         string
+	}{
+		{"0", "0", ""},
+		{"0.000", "0", ""},
+		{"1", "1", ""},
+		{"\"123.456\"", "123.456", ""},
+		{" +1000 ", "1000", ""},
+		{"1e3", "1000", ""},
+		{"1.5e-3", "0.0015", ""},
+		{"1E2", "100", ""},
+		{"", "", "empty input"},
+		{"-1", "", "negative value"},
+		{"1.2.3", "", "parsing failed: unexpected delimeter at pos 4"},
+		{"12a", "", "parsing failed: unexpected symbol 'a' at pos 3"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := FromString(test.s)
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, v.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+	a.Panics(func() { MustFromString("abc") })
+	a.Equal("0.1", MustFromFloat64(0.1).String())
+	a.Panics(func() { MustFromFloat64(-1) })
+	a.Equal(Max, FromMantAndExp(1, int32(maxExponent+digitsInMaxMantissa)))
+	a.Equal(zero, FromMantAndExp(1, minExponent-1))
+	a.Equal(FromUint64(1), FromMantAndExp(1000, -3))
+	a.Equal(uint64(12), MustFromString("12.5").Uint64())
+	a.Equal(uint64(0), Min.Uint64())
+	a.Equal(uint64(0), FromMantAndExp(1, -25).Uint64())
+
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 1000; i++ {
+		v := randomValue(rnd)
+		for _, f := range []string{"%s", "%v"} {
+			parsed, err := FromString(fmt.Sprintf(f, v))
+			if a.NoError(err) {
+				a.True(v.Eq(parsed), "%v != %v", v, parsed)
+			}
+		}
+	}
+}
+
+func TestValueJSON(t *testing.T) {
+	a := assert.New(t)
+	defer func(mode int) { dfp.JSONMode = mode }(dfp.JSONMode)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for _, mode := range []int{dfp.FormatString, dfp.FormatJSONObject, dfp.JSONModeCompact} {
+		dfp.JSONMode = mode
+		for i := 0; i < 100; i++ {
+			v := randomValue(rnd)
+			data, err := json.Marshal(v)
+			if !a.NoError(err) {
+				continue
+			}
+			var unmarshaled Value
+			if a.NoError(json.Unmarshal(data, &unmarshaled)) {
+				a.True(v.Eq(unmarshaled), "%v != %v", v, unmarshaled)
+			}
+		}
+	}
+	var v Value
+	a.Error(v.UnmarshalJSON(nil))
+	a.Error(json.Unmarshal([]byte("\"-1\""), &v))
+}
+
+func TestValueArithmetic(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("1.12", MustFromString("1.125").Round(2).String())
+	a.Equal("1.13", MustFromString("1.125").RoundTo(2, dfp.RoundHalfUp).String())
+	a.Equal("1.12", MustFromString("1.125").Floor(2).String())
+	a.Equal("1.13", MustFromString("1.121").Ceil(2).String())
+	a.Equal("1300", MustFromString("1251").Round(-2).String())
+	a.Equal("0.125", FromUint64(1).Div(FromUint64(8)).String())
+	a.Equal("0.13", FromUint64(1).Quo(FromUint64(8), 2, dfp.RoundHalfUp).String())
+	a.Equal(Max, Max.Add(Max))
+	a.Equal(Max, Max.Mul(FromUint64(10)))
+	a.Equal(zero, Min.Mul(Min))
+	a.Panics(func() { FromUint64(1).Div(zero) })
+
+	// values less than that can lose significant digits.
+	minPrecise := pow10Rat(minExponent + digitsInMaxMantissa)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v1, v2 := randomValue(rnd), randomValue(rnd)
+		r1, r2 := toRat(v1), toRat(v2)
+		if !a.Equal(r1.Cmp(r2), v1.Cmp(v2), "%v cmp %v", v1, v2) {
+			break
+		}
+
+		larger := v1
+		if v1.Cmp(v2) < 0 {
+			larger = v2
+		}
+
+		// the results and the operands are truncated, so the results can differ from the exact ones,
+		// but not more than by 2 ulps of the result or, for addition and subtraction, of the larger operand.
+		inRange := func(op string, result Value, exact *big.Rat) bool {
+			if result == Max || exact.Cmp(minPrecise) < 0 {
+				return true
+			}
+			tolerance := ulp(result)
+			if op == "+" || op == "-" {
+				if u := ulp(larger); u.Cmp(tolerance) > 0 {
+					tolerance = u
+				}
+			}
+			diff := new(big.Rat).Sub(exact, toRat(result))
+			return a.True(diff.Abs(diff).Cmp(tolerance.Mul(tolerance, big.NewRat(2, 1))) <= 0,
+				"%v %s %v = %v, expected %v", v1, op, v2, result, new(big.Float).SetRat(exact))
+		}
+		if !inRange("+", v1.Add(v2), new(big.Rat).Add(r1, r2)) {
+			break
+		}
+		diff, neg := v1.Sub(v2)
+		if !inRange("-", diff, new(big.Rat).Abs(new(big.Rat).Sub(r1, r2))) || !a.Equal(r1.Cmp(r2) < 0, neg) {
+			break
+		}
+		if !inRange("*", v1.Mul(v2), new(big.Rat).Mul(r1, r2)) {
+			break
+		}
+		if !v2.IsZero() && !inRange("/", v1.Div(v2), new(big.Rat).Quo(r1, r2)) {
+			break
+		}
+	}
+}
+
+func TestValueRounding(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v := randomValue(rnd)
+		_, e := split(v)
+		prec := -e - rnd.Intn(digitsInMaxMantissa+1)
+		step := pow10Rat(-prec)
+		exact := toRat(v)
+		floor, ceil := v.Floor(prec), v.Ceil(prec)
+		if floor == Max || ceil == Max {
+			continue
+		}
+		rf, rc := toRat(floor), toRat(ceil)
+		a.True(rf.Cmp(exact) <= 0 && exact.Cmp(rc) <= 0, "%v: %v %v", v, floor, ceil)
+		d := new(big.Rat).Sub(rc, rf)
+		if exact.Cmp(rf) == 0 {
+			a.Equal(0, d.Sign(), "%v: %v %v", v, floor, ceil)
+		} else {
+			a.Equal(0, d.Cmp(step), "%v: %v %v", v, floor, ceil)
+		}
+		// compare the distance to floor and ceil to find the nearest value.
+		toFloor, toCeil := new(big.Rat).Sub(exact, rf), new(big.Rat).Sub(rc, exact)
+		nearest, halfUp := floor, floor
+		switch toFloor.Cmp(toCeil) {
+		case 1:
+			nearest, halfUp = ceil, ceil
+		case 0:
+			halfUp = ceil
+		}
+		a.True(nearest.Eq(v.Round(prec)), "%v: %v != %v", v, nearest, v.Round(prec))
+		a.True(halfUp.Eq(v.RoundTo(prec, dfp.RoundHalfUp)), "%v: %v != %v", v, halfUp, v.RoundTo(prec, dfp.RoundHalfUp))
+	}
+}
+
+func TestValueConversions(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 1000; i++ {
+		v := randomValue(rnd)
+		converted, exact := v.ToValue()
+		back, backExact := FromValue(converted)
+		a.Equal(exact, backExact && back.Eq(v), "%v: %v %v", v, converted, back)
+		if exact {
+			a.Equal(v.String(), converted.String())
+		}
+	}
+	v, exact := FromValue(dfp.MustFromString("1.5"))
+	a.True(exact)
+	a.Equal("1.5", v.String())
+}
+`
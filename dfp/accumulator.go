@@ -0,0 +1,230 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Accumulator calculates an exact sum of values with arbitrary exponents.
+// Unlike Add, it never truncates digits, and the sum can be negative.
+// The sum is kept as a 128-bit integer with a decimal exponent, and switches to big.Int,
+// if 128 bits are not enough.
+// The zero value is an empty accumulator ready to use.
+type Accumulator struct {
+	// hi:lo is the absolute value of the sum, if wide is nil.
+	hi, lo uint64
+	neg    bool
+	wide   *big.Int
+	exp    int32
+}
+
+// Add adds a value to the sum.
+func (a *Accumulator) Add(v Value) {
+	a.addValue(v, false)
+}
+
+// Sub subtracts a value from the sum.
+func (a *Accumulator) Sub(v Value) {
+	a.addValue(v, true)
+}
+
+// Merge adds the sum of another accumulator to the sum.
+func (a *Accumulator) Merge(other *Accumulator) {
+	if other.wide != nil {
+		a.addWide(new(big.Int).Set(other.wide), other.exp)
+		return
+	}
+	a.add(other.hi, other.lo, other.exp, other.neg)
+}
+
+// Reset sets the sum to zero.
+func (a *Accumulator) Reset() {
+	*a = Accumulator{}
+}
+
+// IsZero returns true, if the sum is zero.
+func (a *Accumulator) IsZero() bool {
+	if a.wide != nil {
+		return a.wide.Sign() == 0
+	}
+	return a.hi == 0 && a.lo == 0
+}
+
+// AddMul adds the product of two values to the sum. The product is calculated exactly.
+func (a *Accumulator) AddMul(x, y Value) {
+	a.addProduct(x, y, false)
+}
+
+// SubMul subtracts the product of two values from the sum. The product is calculated exactly.
+func (a *Accumulator) SubMul(x, y Value) {
+	a.addProduct(x, y, true)
+}
+
+// Result returns the absolute value of the sum rounded to prec decimal places using given rounding mode,
+// and a boolean flag indicating that the sum is negative.
+// If the sum has more digits, than the mantissa can hold, the least significant digits are also rounded.
+// If the sum overflows Max, Max is returned. exact is false, if the result was rounded.
+// Note that prec can be negative.
+func (a *Accumulator) Result(prec int, mode RoundingMode) (v Value, neg bool, exact bool) {
+	return roundFrac(a.bigInt(), big.NewInt(1), int(a.exp), prec, mode)
+}
+
+// BigRat returns the sum as a big.Rat number. The conversion is exact.
+func (a *Accumulator) BigRat() *big.Rat {
+	r := new(big.Rat).SetInt(a.bigInt())
+	if a.exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(bigPow10(int(a.exp))))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(bigPow10(int(-a.exp))))
+}
+
+// Quo returns the absolute value of the quotient of the sums of a and b, rounded as Result does,
+// and a boolean flag indicating that the quotient is negative. If the sum of b is zero, Quo panics.
+func (a *Accumulator) Quo(b *Accumulator, prec int, mode RoundingMode) (v Value, neg bool, exact bool) {
+	den := b.bigInt()
+	if den.Sign() == 0 {
+		panic("division by zero")
+	}
+	return roundFrac(a.bigInt(), den, int(a.exp)-int(b.exp), prec, mode)
+}
+
+// roundFrac returns the absolute value of num/den * 10^exp rounded to prec decimal places,
+// and a boolean flag indicating that it is negative.
+func roundFrac(num, den *big.Int, exp, prec int, mode RoundingMode) (v Value, neg bool, exact bool) {
+	neg = num.Sign()*den.Sign() < 0
+	num.Abs(num)
+	den.Abs(den)
+	if exp >= 0 {
+		num.Mul(num, bigPow10(exp))
+	} else {
+		den.Mul(den, bigPow10(-exp))
+	}
+	minExp := -prec
+	if minExp < minExponent {
+		minExp = minExponent
+	}
+	v, exact = fromBigFrac(num, den, minExp, int(mode))
+	if v.IsZero() {
+		neg = false
+	}
+	return v, neg, exact
+}
+
+func (a *Accumulator) addValue(v Value, neg bool) {
+	m, e := split(v.Normalized())
+	if m == 0 {
+		return
+	}
+	a.add(0, m, int32(e), neg)
+}
+
+func (a *Accumulator) addProduct(x, y Value, neg bool) {
+	m1, e1 := split(x.Normalized())
+	m2, e2 := split(y.Normalized())
+	hi, lo := bits.Mul64(m1, m2)
+	a.add(hi, lo, int32(e1)+int32(e2), neg)
+}
+
+// add adds (-1)^neg * hi:lo * 10^exp to the sum.
+func (a *Accumulator) add(hi, lo uint64, exp int32, neg bool) {
+	if hi == 0 && lo == 0 {
+		return
+	}
+	if a.wide != nil {
+		a.addWide(bigFromUint128(hi, lo, neg), exp)
+		return
+	}
+	if a.hi == 0 && a.lo == 0 {
+		a.hi, a.lo, a.neg, a.exp = hi, lo, neg, exp
+		return
+	}
+	// align the exponents, so that the result has the smallest one.
+	ok := true
+	if exp < a.exp {
+		var shi, slo uint64
+		if shi, slo, ok = mulPow10(a.hi, a.lo, int(a.exp-exp)); ok {
+			a.hi, a.lo, a.exp = shi, slo, exp
+		}
+	} else if exp > a.exp {
+		var shi, slo uint64
+		if shi, slo, ok = mulPow10(hi, lo, int(exp-a.exp)); ok {
+			hi, lo, exp = shi, slo, a.exp
+		}
+	}
+	if ok && neg == a.neg {
+		var carry uint64
+		a.lo, carry = bits.Add64(a.lo, lo, 0)
+		if a.hi, carry = bits.Add64(a.hi, hi, carry); carry == 0 {
+			return
+		}
+		// undo the addition, and fall back to big.Int.
+		a.lo, carry = bits.Sub64(a.lo, lo, 0)
+		a.hi, _ = bits.Sub64(a.hi, hi, carry)
+	} else if ok {
+		if a.hi < hi || a.hi == hi && a.lo < lo {
+			a.hi, a.lo, hi, lo = hi, lo, a.hi, a.lo
+			a.neg = neg
+		}
+		var borrow uint64
+		a.lo, borrow = bits.Sub64(a.lo, lo, 0)
+		a.hi, _ = bits.Sub64(a.hi, hi, borrow)
+		return
+	}
+	a.wide = bigFromUint128(a.hi, a.lo, a.neg)
+	a.hi, a.lo, a.neg = 0, 0, false
+	a.addWide(bigFromUint128(hi, lo, neg), exp)
+}
+
+// addWide adds x * 10^exp to the sum, which is kept in a.wide. x can be modified.
+func (a *Accumulator) addWide(x *big.Int, exp int32) {
+	if a.wide == nil {
+		a.wide = bigFromUint128(a.hi, a.lo, a.neg)
+		a.hi, a.lo, a.neg = 0, 0, false
+	}
+	if exp < a.exp {
+		a.wide.Mul(a.wide, bigPow10(int(a.exp-exp)))
+		a.exp = exp
+	} else if exp > a.exp {
+		x.Mul(x, bigPow10(int(exp-a.exp)))
+	}
+	a.wide.Add(a.wide, x)
+}
+
+// bigInt returns the sum as (-1)^neg * hi:lo or a copy of wide.
+func (a *Accumulator) bigInt() *big.Int {
+	if a.wide != nil {
+		return new(big.Int).Set(a.wide)
+	}
+	return bigFromUint128(a.hi, a.lo, a.neg)
+}
+
+func bigFromUint128(hi, lo uint64, neg bool) *big.Int {
+	x := new(big.Int).SetUint64(hi)
+	x.Lsh(x, 64).Or(x, new(big.Int).SetUint64(lo))
+	if neg {
+		x.Neg(x)
+	}
+	return x
+}
+
+// mulPow10 returns hi:lo * 10^pow, and false, if the result does not fit 128 bits.
+func mulPow10(hi, lo uint64, pow int) (rhi, rlo uint64, ok bool) {
+	for pow > 0 {
+		step := min(pow, 18)
+		p := pow10(step)
+		h1, l1 := bits.Mul64(lo, p)
+		h2, l2 := bits.Mul64(hi, p)
+		if h2 != 0 {
+			return 0, 0, false
+		}
+		var carry uint64
+		if hi, carry = bits.Add64(l2, h1, 0); carry != 0 {
+			return 0, 0, false
+		}
+		lo = l1
+		pow -= step
+	}
+	return hi, lo, true
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccumulator(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		add, sub []string
+		prec     int
+		mode     RoundingMode
+		expected string
+		neg      bool
+		exact    bool
+	}{
+		{nil, nil, 2, RoundDown, "0", false, true},
+		{[]string{"1.25", "2.5"}, []string{"0.75"}, 2, RoundDown, "3", false, true},
+		{[]string{"1.25"}, []string{"2.5"}, 2, RoundDown, "1.25", true, true},
+		{[]string{"1.25"}, []string{"1.25"}, 2, RoundDown, "0", false, true},
+		{[]string{"1.25"}, nil, 1, RoundDown, "1.2", false, false},
+		{[]string{"1.25"}, nil, 1, RoundNearest, "1.2", false, false},
+		{[]string{"1.25"}, nil, 1, RoundHalfUp, "1.3", false, false},
+		{[]string{"1.21"}, nil, 1, RoundUp, "1.3", false, false},
+		{nil, []string{"1.25"}, 1, RoundUp, "1.3", true, false},
+		{[]string{"1250"}, nil, -2, RoundHalfUp, "1300", false, false},
+		{nil, []string{"0.001"}, 2, RoundDown, "0", false, false},
+		{[]string{"72057594037927935", "1"}, nil, 0, RoundDown, "72057594037927930", false, false},
+		{[]string{"1e20", "1e-20"}, []string{"1e20"}, 30, RoundDown, "0.00000000000000000001", false, true},
+		{[]string{"1e20", "1e-20"}, nil, 30, RoundDown, "100000000000000000000", false, false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var acc Accumulator
+			for _, s := range test.add {
+				acc.Add(MustFromString(s))
+			}
+			for _, s := range test.sub {
+				acc.Sub(MustFromString(s))
+			}
+			v, neg, exact := acc.Result(test.prec, test.mode)
+			a.Equal(test.expected, v.String())
+			expected := new(big.Rat)
+			for _, s := range test.add {
+				expected.Add(expected, MustFromString(s).BigRat())
+			}
+			for _, s := range test.sub {
+				expected.Sub(expected, MustFromString(s).BigRat())
+			}
+			a.Equal(expected.String(), acc.BigRat().String())
+			a.Equal(test.neg, neg)
+			a.Equal(test.exact, exact)
+		})
+	}
+}
+
+func TestAccumulatorWide(t *testing.T) {
+	a := assert.New(t)
+	var acc Accumulator
+	acc.Add(Max)
+	acc.Add(Min)
+	a.NotNil(acc.wide)
+	v, neg, exact := acc.Result(127, RoundDown)
+	a.True(Max.Eq(v))
+	a.False(neg)
+	a.False(exact)
+	acc.Sub(Max)
+	v, neg, exact = acc.Result(127, RoundDown)
+	a.True(Min.Eq(v))
+	a.False(neg)
+	a.True(exact)
+
+	acc.Reset()
+	a.True(acc.IsZero())
+	acc.Add(Max)
+	acc.Add(Max)
+	v, _, exact = acc.Result(0, RoundDown)
+	a.True(Max.Eq(v))
+	a.False(exact)
+}
+
+func TestAccumulatorMerge(t *testing.T) {
+	a := assert.New(t)
+	var acc1, acc2, acc3 Accumulator
+	acc1.Add(MustFromString("1.5"))
+	acc2.Sub(MustFromString("0.25"))
+	acc3.Add(Max)
+	acc3.Add(Min)
+	acc1.Merge(&acc2)
+	v, neg, _ := acc1.Result(10, RoundDown)
+	a.Equal("1.25", v.String())
+	a.False(neg)
+	acc1.Merge(&acc1)
+	v, _, _ = acc1.Result(10, RoundDown)
+	a.Equal("2.5", v.String())
+	acc1.Merge(&acc3)
+	acc1.Sub(Max)
+	acc1.Sub(Min)
+	v, _, exact := acc1.Result(10, RoundDown)
+	a.Equal("2.5", v.String())
+	a.True(exact)
+}
+
+func TestAccumulatorMulQuo(t *testing.T) {
+	a := assert.New(t)
+	var pq, q Accumulator
+	pq.AddMul(MustFromString("72057594037927935"), MustFromString("72057594037927935"))
+	v, _, exact := pq.Result(0, RoundDown)
+	a.Equal("5192296858534827400000000000000000", v.String())
+	a.False(exact)
+	pq.SubMul(MustFromString("72057594037927935"), MustFromString("72057594037927935"))
+	a.True(pq.IsZero())
+
+	// VWAP: (1.25 * 100 + 1.5 * 300) / 400 = 1.4375
+	pq.AddMul(MustFromString("1.25"), MustFromString("100"))
+	pq.AddMul(MustFromString("1.5"), MustFromString("300"))
+	q.Add(MustFromString("100"))
+	q.Add(MustFromString("300"))
+	v, neg, exact := pq.Quo(&q, 2, RoundHalfUp)
+	a.Equal("1.44", v.String())
+	a.False(neg)
+	a.False(exact)
+	v, _, exact = pq.Quo(&q, 4, RoundHalfUp)
+	a.Equal("1.4375", v.String())
+	a.True(exact)
+	q.Sub(MustFromString("500"))
+	v, neg, _ = pq.Quo(&q, 2, RoundDown)
+	a.Equal("5.75", v.String())
+	a.True(neg)
+	v, _, _ = q.Quo(&pq, 30, RoundDown)
+	a.Equal("0.17391304347826086", v.String())
+
+	var empty Accumulator
+	a.Panics(func() { pq.Quo(&empty, 2, RoundDown) })
+	v, neg, exact = empty.Quo(&pq, 2, RoundDown)
+	a.True(v.IsZero())
+	a.False(neg)
+	a.True(exact)
+}
+
+func TestAccumulatorRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 100; i++ {
+		var acc, part Accumulator
+		sum := new(big.Rat)
+		expRange := 1 + rnd.Intn(256)
+		for j := 0; j < 1000; j++ {
+			v := fromMantAndExp(uint64(rnd.Int63n(maxMantissa))>>uint(rnd.Intn(56)), expType(rnd.Intn(expRange)+minExponent))
+			y := fromMantAndExp(uint64(rnd.Int63n(maxMantissa))>>uint(rnd.Intn(56)), expType(rnd.Intn(expRange)+minExponent))
+			target := &acc
+			if rnd.Intn(2) == 0 {
+				target = &part
+			}
+			switch rnd.Intn(4) {
+			case 0:
+				target.Add(v)
+				sum.Add(sum, v.BigRat())
+			case 1:
+				target.Sub(v)
+				sum.Sub(sum, v.BigRat())
+			case 2:
+				target.AddMul(v, y)
+				sum.Add(sum, new(big.Rat).Mul(v.BigRat(), y.BigRat()))
+			case 3:
+				target.SubMul(v, y)
+				sum.Sub(sum, new(big.Rat).Mul(v.BigRat(), y.BigRat()))
+			}
+		}
+		acc.Merge(&part)
+		for _, mode := range []RoundingMode{RoundDown, RoundNearest, RoundUp, RoundHalfUp} {
+			expected, expectedExact, _ := FromBigRat(new(big.Rat).Abs(sum), mode)
+			v, neg, exact := acc.Result(-minExponent, mode)
+			a.True(expected.Eq(v), "%v != %v", expected, v)
+			a.Equal(sum.Sign() < 0 && !v.IsZero(), neg)
+			a.Equal(expectedExact, exact)
+		}
+	}
+}
+
+func BenchmarkAccumulator(b *testing.B) {
+	values := make([]Value, 10000)
+	for i := range values {
+		values[i] = FromMantAndExp(uint64(i*1234567), -int32(i%5))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var acc Accumulator
+		for _, v := range values {
+			acc.Add(v)
+		}
+	}
+}
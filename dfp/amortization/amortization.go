@@ -0,0 +1,148 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package amortization generates loan repayment schedules using decimal floating-point values.
+package amortization
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+// ratePrec is the number of decimal places used for a periodic interest rate.
+// The rate is rounded to the mantissa capacity anyway, so this is just 'as precise as possible'.
+const ratePrec = 32
+
+var (
+	one = dfp.FromUint64(1)
+)
+
+// Kind defines the way a loan is repaid.
+type Kind int
+
+const (
+	// Annuity loans are repaid with equal payments, each covering the interest and a part of the principal.
+	Annuity Kind = iota
+	// Linear loans are repaid with equal principal parts, so that the payments decrease over time.
+	Linear
+	// Bullet loans are repaid with interest-only payments, and the principal is repaid with the last payment.
+	Bullet
+)
+
+// Loan describes the parameters of a loan.
+type Loan struct {
+	// Kind is the repayment type.
+	Kind Kind
+	// Principal is the amount borrowed.
+	Principal dfp.Value
+	// Rate is the nominal annual interest rate, e.g. 0.05 for 5%.
+	Rate dfp.Value
+	// Periods is the number of payments.
+	Periods int
+	// PeriodsPerYear is the number of payments per year. Must divide 12, e.g. 12 for monthly payments.
+	PeriodsPerYear int
+	// Start is the date the loan is issued. The first payment is due one period later.
+	Start time.Time
+}
+
+// Row is a single entry of a repayment schedule.
+type Row struct {
+	// Period is the payment number, starting from 1.
+	Period int
+	// Due is the payment date.
+	Due time.Time
+	// Payment is the total amount paid, Interest + Principal.
+	Payment dfp.Value
+	// Interest is the interest part of the payment.
+	Interest dfp.Value
+	// Principal is the principal part of the payment.
+	Principal dfp.Value
+	// Balance is the outstanding principal after the payment.
+	Balance dfp.Value
+}
+
+// Schedule returns the repayment schedule for the loan.
+// All the amounts are rounded to prec decimal places with given rounding mode.
+// The principal part of the last payment is adjusted, so that the balance ends at exactly zero.
+func Schedule(l Loan, prec int, mode dfp.RoundingMode) ([]Row, error) {
+	if l.Periods <= 0 {
+		return nil, fmt.Errorf("bad number of periods: %d", l.Periods)
+	}
+	if l.PeriodsPerYear <= 0 || 12%l.PeriodsPerYear != 0 {
+		return nil, fmt.Errorf("bad number of periods per year: %d", l.PeriodsPerYear)
+	}
+	rate := l.Rate.Quo(dfp.FromUint64(uint64(l.PeriodsPerYear)), ratePrec, dfp.RoundNearest)
+	var payment dfp.Value
+	switch l.Kind {
+	case Annuity:
+		payment = annuityPayment(l.Principal, rate, l.Periods, prec, mode)
+	case Linear:
+		payment = l.Principal.Quo(dfp.FromUint64(uint64(l.Periods)), prec, mode)
+	case Bullet:
+	default:
+		return nil, fmt.Errorf("unknown loan kind: %d", l.Kind)
+	}
+	rows := make([]Row, 0, l.Periods)
+	balance := l.Principal
+	for i := 1; i <= l.Periods; i++ {
+		row := Row{
+			Period:   i,
+			Due:      addMonths(l.Start, i*12/l.PeriodsPerYear),
+			Interest: balance.Mul(rate).RoundTo(prec, mode),
+		}
+		switch {
+		case i == l.Periods:
+			row.Principal = balance
+		case l.Kind == Annuity:
+			principal, neg := payment.Sub(row.Interest)
+			if neg {
+				return nil, fmt.Errorf("payment %s does not cover interest %s in period %d", payment, row.Interest, i)
+			}
+			row.Principal = principal
+		case l.Kind == Linear:
+			row.Principal = payment
+		}
+		if balance.Cmp(row.Principal) < 0 { // may happen due to rounding
+			row.Principal = balance
+		}
+		balance, _ = balance.Sub(row.Principal)
+		row.Payment = row.Interest.Add(row.Principal)
+		row.Balance = balance
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// annuityPayment calculates p * r * (1+r)^n / ((1+r)^n - 1).
+func annuityPayment(p, r dfp.Value, n, prec int, mode dfp.RoundingMode) dfp.Value {
+	if r.IsZero() {
+		return p.Quo(dfp.FromUint64(uint64(n)), prec, mode)
+	}
+	f := pow(one.Add(r), n)
+	d, _ := f.Sub(one)
+	return p.Mul(r).Mul(f).Quo(d, prec, mode)
+}
+
+// pow calculates v^n by squaring.
+func pow(v dfp.Value, n int) dfp.Value {
+	result := one
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result = result.Mul(v)
+		}
+		v = v.Mul(v)
+	}
+	return result
+}
+
+// addMonths adds given number of months to t.
+// Unlike time.AddDate, it does not overflow into the next month, but sticks to the last day of the month.
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
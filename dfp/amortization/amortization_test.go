@@ -0,0 +1,121 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package amortization
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func TestScheduleAnnuity(t *testing.T) {
+	a := assert.New(t)
+	l := Loan{
+		Kind:           Annuity,
+		Principal:      dfp.MustFromString("1000"),
+		Rate:           dfp.MustFromString("0.12"),
+		Periods:        12,
+		PeriodsPerYear: 12,
+		Start:          time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	rows, err := Schedule(l, 2, dfp.RoundHalfUp)
+	if !a.NoError(err) || !a.Len(rows, 12) {
+		return
+	}
+	expected := []struct {
+		payment, interest, principal, balance string
+	}{
+		{"88.85", "10", "78.85", "921.15"},
+		{"88.85", "9.21", "79.64", "841.51"},
+		{"88.85", "8.42", "80.43", "761.08"},
+		{"88.85", "7.61", "81.24", "679.84"},
+		{"88.85", "6.8", "82.05", "597.79"},
+		{"88.85", "5.98", "82.87", "514.92"},
+		{"88.85", "5.15", "83.7", "431.22"},
+		{"88.85", "4.31", "84.54", "346.68"},
+		{"88.85", "3.47", "85.38", "261.3"},
+		{"88.85", "2.61", "86.24", "175.06"},
+		{"88.85", "1.75", "87.1", "87.96"},
+		{"88.84", "0.88", "87.96", "0"},
+	}
+	for i, row := range rows {
+		a.Equal(i+1, row.Period)
+		a.Equal(expected[i].payment, row.Payment.String(), "period %d", row.Period)
+		a.Equal(expected[i].interest, row.Interest.String(), "period %d", row.Period)
+		a.Equal(expected[i].principal, row.Principal.String(), "period %d", row.Period)
+		a.Equal(expected[i].balance, row.Balance.String(), "period %d", row.Period)
+	}
+	a.Equal(time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), rows[0].Due)
+	a.Equal(time.Date(2020, 3, 31, 0, 0, 0, 0, time.UTC), rows[1].Due)
+	a.Equal(time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC), rows[11].Due)
+}
+
+func TestScheduleKinds(t *testing.T) {
+	a := assert.New(t)
+	start := time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		l        Loan
+		payments []string
+		balances []string
+	}{
+		{
+			Loan{Kind: Annuity, Principal: dfp.MustFromString("1000"), Periods: 3, PeriodsPerYear: 12, Start: start},
+			[]string{"333.33", "333.33", "333.34"},
+			[]string{"666.67", "333.34", "0"},
+		},
+		{
+			Loan{
+				Kind: Linear, Principal: dfp.MustFromString("1200"), Rate: dfp.MustFromString("0.12"),
+				Periods: 3, PeriodsPerYear: 4, Start: start,
+			},
+			[]string{"436", "424", "412"},
+			[]string{"800", "400", "0"},
+		},
+		{
+			Loan{
+				Kind: Linear, Principal: dfp.MustFromString("100"), Rate: dfp.MustFromString("0.06"),
+				Periods: 3, PeriodsPerYear: 1, Start: start,
+			},
+			[]string{"39.33", "37.33", "35.34"},
+			[]string{"66.67", "33.34", "0"},
+		},
+		{
+			Loan{
+				Kind: Bullet, Principal: dfp.MustFromString("1000"), Rate: dfp.MustFromString("0.05"),
+				Periods: 2, PeriodsPerYear: 2, Start: start,
+			},
+			[]string{"25", "1025"},
+			[]string{"1000", "0"},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			rows, err := Schedule(test.l, 2, dfp.RoundDown)
+			if !a.NoError(err) || !a.Len(rows, len(test.payments)) {
+				return
+			}
+			total := dfp.FromUint64(0)
+			for i, row := range rows {
+				a.Equal(test.payments[i], row.Payment.String())
+				a.Equal(test.balances[i], row.Balance.String())
+				a.True(row.Payment.Eq(row.Interest.Add(row.Principal)))
+				total = total.Add(row.Principal)
+			}
+			a.True(total.Eq(test.l.Principal))
+		})
+	}
+}
+
+func TestScheduleErrors(t *testing.T) {
+	a := assert.New(t)
+	_, err := Schedule(Loan{Periods: 0, PeriodsPerYear: 12}, 2, dfp.RoundDown)
+	a.EqualError(err, "bad number of periods: 0")
+	_, err = Schedule(Loan{Periods: 1, PeriodsPerYear: 5}, 2, dfp.RoundDown)
+	a.EqualError(err, "bad number of periods per year: 5")
+	_, err = Schedule(Loan{Kind: Kind(10), Periods: 1, PeriodsPerYear: 12}, 2, dfp.RoundDown)
+	a.EqualError(err, "unknown loan kind: 10")
+}
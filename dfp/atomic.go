@@ -0,0 +1,86 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"sync/atomic"
+)
+
+// AtomicValue is a value, which can be safely used by multiple goroutines without locking.
+// The zero value is zero. AtomicValue must not be copied after first use.
+type AtomicValue struct {
+	// v is the first field to be 64-bit aligned on 32-bit platforms.
+	v uint64
+}
+
+// NewAtomicValue returns an atomic value initialized with v.
+func NewAtomicValue(v Value) *AtomicValue {
+	return &AtomicValue{v: uint64(v)}
+}
+
+// Load atomically loads the value.
+func (a *AtomicValue) Load() Value {
+	return Value(atomic.LoadUint64(&a.v))
+}
+
+// Store atomically stores the value.
+func (a *AtomicValue) Store(v Value) {
+	atomic.StoreUint64(&a.v, uint64(v))
+}
+
+// Swap atomically stores new value, and returns the previous one.
+func (a *AtomicValue) Swap(new Value) (old Value) {
+	return Value(atomic.SwapUint64(&a.v, uint64(new)))
+}
+
+// CompareAndSwap stores new value, if the current value is numerically equal to old,
+// so that 1.5 and 1.50 are considered equal. It returns true, if the value was swapped.
+func (a *AtomicValue) CompareAndSwap(old, new Value) (swapped bool) {
+	for {
+		cur := atomic.LoadUint64(&a.v)
+		if !Value(cur).Eq(old) {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&a.v, cur, uint64(new)) {
+			return true
+		}
+	}
+}
+
+// Add atomically adds delta to the value, and returns the new value. See Value.Add for details.
+func (a *AtomicValue) Add(delta Value) (new Value) {
+	return a.update(func(cur Value) Value {
+		return cur.Add(delta)
+	})
+}
+
+// Max atomically sets the value to the maximum of the current value and v, and returns the new value.
+func (a *AtomicValue) Max(v Value) (new Value) {
+	return a.update(func(cur Value) Value {
+		if v.Cmp(cur) > 0 {
+			return v
+		}
+		return cur
+	})
+}
+
+// Min atomically sets the value to the minimum of the current value and v, and returns the new value.
+func (a *AtomicValue) Min(v Value) (new Value) {
+	return a.update(func(cur Value) Value {
+		if v.Cmp(cur) < 0 {
+			return v
+		}
+		return cur
+	})
+}
+
+// update replaces the value with f(value) in a CAS loop, and returns the new value.
+func (a *AtomicValue) update(f func(cur Value) Value) Value {
+	for {
+		cur := atomic.LoadUint64(&a.v)
+		new := f(Value(cur))
+		if uint64(new) == cur || atomic.CompareAndSwapUint64(&a.v, cur, uint64(new)) {
+			return new
+		}
+	}
+}
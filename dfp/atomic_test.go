@@ -0,0 +1,77 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicValue(t *testing.T) {
+	a := assert.New(t)
+	var av AtomicValue
+	a.True(av.Load().IsZero())
+	av.Store(MustFromString("1.5"))
+	a.Equal("1.5", av.Load().String())
+	a.Equal("1.5", av.Swap(MustFromString("2")).String())
+	a.Equal("2", av.Load().String())
+
+	a.False(av.CompareAndSwap(MustFromString("1.5"), MustFromString("3")))
+	a.Equal("2", av.Load().String())
+	a.True(av.CompareAndSwap(FromMantAndExp(200, -2), MustFromString("3")))
+	a.Equal("3", av.Load().String())
+
+	a.Equal("4.25", av.Add(MustFromString("1.25")).String())
+	a.Equal("5", av.Max(MustFromString("5")).String())
+	a.Equal("5", av.Max(MustFromString("4")).String())
+	a.Equal("0.5", av.Min(MustFromString("0.5")).String())
+	a.Equal("0.5", av.Min(MustFromString("0.75")).String())
+	a.Equal("0.5", NewAtomicValue(MustFromString("0.5")).Load().String())
+}
+
+func TestAtomicValueConcurrent(t *testing.T) {
+	a := assert.New(t)
+	const (
+		goroutines = 8
+		iterations = 1000
+	)
+	var sum, max, min, last AtomicValue
+	min.Store(Max)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				v := FromMantAndExp(uint64(i*iterations+j+1), -2)
+				sum.Add(MustFromString("0.01"))
+				max.Max(v)
+				min.Min(v)
+				last.Store(v)
+				for {
+					cur := last.Load()
+					if last.CompareAndSwap(cur, cur.Add(v)) {
+						break
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	a.Equal("80", sum.Load().String())
+	a.Equal("80", max.Load().String())
+	a.Equal("0.01", min.Load().String())
+	a.False(last.Load().IsZero())
+}
+
+func BenchmarkAtomicValueAdd(b *testing.B) {
+	var av AtomicValue
+	delta := MustFromString("0.01")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			av.Add(delta)
+		}
+	})
+}
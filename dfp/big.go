@@ -0,0 +1,169 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const (
+	// bigFloat binary exponents, beyond which the values are certainly out of range.
+	// 2^490 > Max, and 2^-440 < Min/2.
+	bigFloatMaxExp = 490
+	bigFloatMinExp = -440
+)
+
+var (
+	bigTen         = big.NewInt(10)
+	bigMaxMantissa = new(big.Int).SetUint64(maxMantissa)
+)
+
+// FromBigInt returns a value for given big.Int number.
+// If the number cannot be precisely represented, it is rounded according to the mode,
+// and false is returned. Returns an error for negative numbers.
+func FromBigInt(i *big.Int, mode RoundingMode) (v Value, exact bool, err error) {
+	if i.Sign() < 0 {
+		return zero, false, fmt.Errorf("negative value")
+	}
+	v, exact = fromBigFrac(i, big.NewInt(1), minExponent, int(mode))
+	return v, exact, nil
+}
+
+// FromBigRat returns a value for given big.Rat number.
+// If the number cannot be precisely represented, it is rounded according to the mode,
+// and false is returned. Returns an error for negative numbers.
+func FromBigRat(r *big.Rat, mode RoundingMode) (v Value, exact bool, err error) {
+	if r.Sign() < 0 {
+		return zero, false, fmt.Errorf("negative value")
+	}
+	v, exact = fromBigFrac(r.Num(), r.Denom(), minExponent, int(mode))
+	return v, exact, nil
+}
+
+// FromBigFloat returns a value for given big.Float number.
+// If the number cannot be precisely represented, it is rounded according to the mode,
+// and false is returned. Returns an error for negative numbers and infinities.
+func FromBigFloat(f *big.Float, mode RoundingMode) (v Value, exact bool, err error) {
+	if f.Sign() < 0 {
+		return zero, false, fmt.Errorf("negative value")
+	}
+	if f.IsInf() {
+		return zero, false, fmt.Errorf("infinity")
+	}
+	if f.Sign() == 0 {
+		return zero, true, nil
+	}
+	// do not build huge rationals for the numbers, that are out of range anyway.
+	// the replacements give the same results for any rounding mode.
+	var r *big.Rat
+	switch e := f.MantExp(nil); {
+	case e > bigFloatMaxExp:
+		r = new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), bigFloatMaxExp))
+	case e < bigFloatMinExp:
+		r = new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), -bigFloatMinExp))
+	default:
+		r, _ = f.Rat(nil)
+	}
+	return FromBigRat(r, mode)
+}
+
+// BigInt returns the integer part of the value.
+// Returns false, if the fractional part was not zero and was truncated.
+func (v Value) BigInt() (i *big.Int, exact bool) {
+	m, e := split(v)
+	i = new(big.Int).SetUint64(m)
+	if e >= 0 {
+		return i.Mul(i, bigPow10(int(e))), true
+	}
+	var rem big.Int
+	i.QuoRem(i, bigPow10(int(-e)), &rem)
+	return i, rem.Sign() == 0
+}
+
+// BigRat returns the value as a big.Rat number. The conversion is exact.
+func (v Value) BigRat() *big.Rat {
+	m, e := split(v)
+	r := new(big.Rat).SetInt(new(big.Int).SetUint64(m))
+	if e >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(bigPow10(int(e))))
+	}
+	return r.Quo(r, new(big.Rat).SetInt(bigPow10(int(-e))))
+}
+
+// BigFloat returns the value as a big.Float number with given precision.
+// If prec is 0, it is set to the larger of the bit lengths of the numerator and the denominator of v.BigRat(),
+// but not less than 64 bits, as big.Float.SetRat does.
+func (v Value) BigFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec).SetRat(v.BigRat())
+}
+
+// fromBigFrac returns a value for num/den, where num >= 0, and den > 0.
+// The exponent of the result is not less, than minExp.
+func fromBigFrac(num, den *big.Int, minExp, mode int) (Value, bool) {
+	if num.Sign() == 0 {
+		return zero, true
+	}
+	// start from an exponent, which is certainly too small, and increase it,
+	// until the quotient fits the mantissa.
+	e := bigDigits(num) - bigDigits(den) - digitsInMaxMantissa - 2
+	if e < minExp {
+		e = minExp
+	}
+	var n, rem, divisor big.Int
+	for ; ; e++ {
+		if e >= 0 {
+			divisor.Mul(den, bigPow10(e))
+			n.QuoRem(num, &divisor, &rem)
+		} else {
+			divisor.Set(den)
+			n.QuoRem(n.Mul(num, bigPow10(-e)), &divisor, &rem)
+		}
+		if n.Cmp(bigMaxMantissa) > 0 {
+			continue
+		}
+		m := n.Uint64()
+		if bigRoundUp(&rem, &divisor, mode) {
+			if m++; m > maxMantissa {
+				continue
+			}
+		}
+		for e > maxExponent && m*10 <= maxMantissa {
+			m *= 10
+			e--
+		}
+		if e > maxExponent {
+			return Max, false
+		}
+		if m == 0 {
+			return zero, false
+		}
+		return fromMantAndExp(m, expType(e)).Normalized(), rem.Sign() == 0
+	}
+}
+
+// bigRoundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func bigRoundUp(r, d *big.Int, mode int) bool {
+	if r.Sign() == 0 {
+		return false
+	}
+	switch mode {
+	case modeRound:
+		return new(big.Int).Lsh(r, 1).Cmp(d) > 0
+	case modeHalfUp:
+		return new(big.Int).Lsh(r, 1).Cmp(d) >= 0
+	case modeCeil:
+		return true
+	default:
+		return false
+	}
+}
+
+// bigDigits returns the number of decimal digits in x, or one less.
+func bigDigits(x *big.Int) int {
+	return x.BitLen() * 1233 >> 12
+}
+
+func bigPow10(pow int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(pow)), nil)
+}
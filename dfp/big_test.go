@@ -0,0 +1,159 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromBig(t *testing.T) {
+	a := assert.New(t)
+	bigInt := func(s string) *big.Rat {
+		i, _ := new(big.Int).SetString(s, 10)
+		return new(big.Rat).SetInt(i)
+	}
+	bigRat := func(s string) *big.Rat {
+		r, _ := new(big.Rat).SetString(s)
+		return r
+	}
+	maxStr := fmt.Sprintf("%v", Max)
+	tests := []struct {
+		r                         *big.Rat
+		down, nearest, up, halfUp string
+		exact                     bool
+	}{
+		{bigInt("0"), "0", "0", "0", "0", true},
+		{bigInt("12345"), "12345e0", "12345e0", "12345e0", "12345e0", true},
+		{bigInt("1" + fmt.Sprintf("%030d", 0)), "1e30", "1e30", "1e30", "1e30", true},
+		{bigRat("1/8"), "125e-3", "125e-3", "125e-3", "125e-3", true},
+		{bigRat("1/3"), "33333333333333333e-17", "33333333333333333e-17", "33333333333333334e-17", "33333333333333333e-17", false},
+		{bigRat("2/3"), "66666666666666666e-17", "66666666666666667e-17", "66666666666666667e-17", "66666666666666667e-17", false},
+		{bigInt("123456789012345678901"), "12345678901234567e4", "12345678901234568e4", "12345678901234568e4", "12345678901234568e4", false},
+		{bigRat("144115188075855871/2"), "72057594037927935e0", "72057594037927935e0", "7205759403792794e1", "7205759403792794e1", false},
+		{bigRat("1e200"), maxStr, maxStr, maxStr, maxStr, false},
+		{bigRat("1e-200"), "0", "0", "1e-127", "0", false},
+		{bigRat("6e-128"), "0", "1e-127", "1e-127", "1e-127", false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for mode, expected := range map[RoundingMode]string{
+				RoundDown:    test.down,
+				RoundNearest: test.nearest,
+				RoundUp:      test.up,
+				RoundHalfUp:  test.halfUp,
+			} {
+				v, exact, err := FromBigRat(test.r, mode)
+				if a.NoError(err) {
+					a.Equal(expected, fmt.Sprintf("%v", v), "mode %d", mode)
+					a.Equal(test.exact, exact, "mode %d", mode)
+				}
+				if test.r.IsInt() {
+					v, exact, err = FromBigInt(test.r.Num(), mode)
+					if a.NoError(err) {
+						a.Equal(expected, fmt.Sprintf("%v", v), "mode %d", mode)
+						a.Equal(test.exact, exact, "mode %d", mode)
+					}
+				}
+			}
+		})
+	}
+	_, _, err := FromBigInt(big.NewInt(-1), RoundDown)
+	a.EqualError(err, "negative value")
+	_, _, err = FromBigRat(big.NewRat(-1, 2), RoundDown)
+	a.EqualError(err, "negative value")
+}
+
+func TestFromBigFloat(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		f        *big.Float
+		mode     RoundingMode
+		expected string
+		exact    bool
+	}{
+		{big.NewFloat(0), RoundDown, "0", true},
+		{big.NewFloat(0.5), RoundDown, "5e-1", true},
+		{big.NewFloat(0.1), RoundDown, "1e-1", false},
+		{big.NewFloat(0.1), RoundNearest, "10000000000000001e-17", false},
+		{new(big.Float).SetMantExp(big.NewFloat(1), 1000), RoundDown, fmt.Sprintf("%v", Max), false},
+		{new(big.Float).SetMantExp(big.NewFloat(1), 481), RoundDown, "62434971006319844e128", false},
+		{new(big.Float).SetMantExp(big.NewFloat(1), -1000), RoundDown, "0", false},
+		{new(big.Float).SetMantExp(big.NewFloat(1), -1000), RoundUp, "1e-127", false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, exact, err := FromBigFloat(test.f, test.mode)
+			if a.NoError(err) {
+				a.Equal(test.expected, fmt.Sprintf("%v", v))
+				a.Equal(test.exact, exact)
+			}
+		})
+	}
+	_, _, err := FromBigFloat(big.NewFloat(-1), RoundDown)
+	a.EqualError(err, "negative value")
+	_, _, err = FromBigFloat(big.NewFloat(math.Inf(1)), RoundDown)
+	a.EqualError(err, "infinity")
+}
+
+func TestToBig(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v          Value
+		rat, int   string
+		exactInt   bool
+		float64Str string
+	}{
+		{zero, "0", "0", true, "0"},
+		{MustFromString("123.45"), "2469/20", "123", false, "123.45"},
+		{MustFromString("0.5"), "1/2", "0", false, "0.5"},
+		{MustFromString("1e20"), "100000000000000000000", "100000000000000000000", true, "1e+20"},
+		{Min, "1/1" + fmt.Sprintf("%0127d", 0), "0", false, "1e-127"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			a.Equal(test.rat, test.v.BigRat().RatString())
+			i, exact := test.v.BigInt()
+			a.Equal(test.int, i.String())
+			a.Equal(test.exactInt, exact)
+			a.Equal(test.float64Str, test.v.BigFloat(64).Text('g', 10))
+		})
+	}
+	a.Equal(Max.BigRat().FloatString(0), fmt.Sprintf("%s", Max))
+	a.Equal(uint(64), MustFromString("1.5").BigFloat(0).Prec())
+	a.Equal(uint(333), MustFromString("1e100").BigFloat(0).Prec())
+	a.Equal(uint(333), MustFromString("1e-100").BigFloat(0).Prec())
+}
+
+func TestBigRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 1000; i++ {
+		v := fromMantAndExp(uint64(rnd.Int63n(maxMantissa)), expType(rnd.Intn(maxExponent-minExponent)+minExponent))
+		r := v.BigRat()
+		for _, mode := range []RoundingMode{RoundDown, RoundNearest, RoundUp, RoundHalfUp} {
+			back, exact, err := FromBigRat(r, mode)
+			if a.NoError(err) {
+				a.True(exact)
+				a.True(v.Eq(back), "%v != %v", v, back)
+			}
+		}
+		// the value lies between the results of rounding down and up, that differ by 1 in the last digit.
+		r.Mul(r, big.NewRat(10, 7))
+		down, _, _ := FromBigRat(r, RoundDown)
+		up, exact, _ := FromBigRat(r, RoundUp)
+		if up == Max || down.IsZero() {
+			continue
+		}
+		a.True(down.BigRat().Cmp(r) <= 0 && r.Cmp(up.BigRat()) <= 0, "%v: %v %v", r, down, up)
+		if !exact {
+			a.True(down.Cmp(up) < 0, "%v: %v %v", r, down, up)
+		}
+	}
+}
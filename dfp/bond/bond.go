@@ -0,0 +1,292 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package bond implements fixed-rate bond analytics using decimal floating-point values:
+// cash flows, price/yield conversions, accrued interest, duration and convexity.
+// Yields are expected to be non-negative, as dfp values can not be negative.
+package bond
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/avdva/numeric/dfp/daycount"
+)
+
+const (
+	// workPrec is the number of decimal places used for intermediate calculations.
+	// Values are rounded to the mantissa capacity anyway, so this is just 'as precise as possible'.
+	workPrec = 32
+	// maxIterations limits the number of iterations for series and Newton's method.
+	maxIterations = 100
+)
+
+var (
+	zero = dfp.FromUint64(0)
+	one  = dfp.FromUint64(1)
+	two  = dfp.FromUint64(2)
+)
+
+// Bond describes a fixed-rate bond.
+type Bond struct {
+	// Face is the face (par) value. Must be positive.
+	Face dfp.Value
+	// Coupon is the annual coupon rate, e.g. 0.05 for 5%.
+	Coupon dfp.Value
+	// Frequency is the number of coupon payments per year. Must divide 12.
+	Frequency int
+	// Maturity is the date the principal is repaid.
+	Maturity time.Time
+	// DayCount is the convention used to calculate accrued interest.
+	// If nil, ACT/ACT ICMA for the current coupon period is used.
+	DayCount daycount.Convention
+}
+
+// CashFlow is a single payment.
+type CashFlow struct {
+	Date   time.Time
+	Amount dfp.Value
+}
+
+// schedule holds the remaining cash flows of a bond.
+type schedule struct {
+	prev, next time.Time
+	flows      []CashFlow
+	// w is the fraction of the current coupon period remaining till the next coupon date.
+	w dfp.Value
+}
+
+// CashFlows returns all the cash flows, that are paid after settlement.
+// The last flow includes the face value.
+func (b Bond) CashFlows(settlement time.Time) ([]CashFlow, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return nil, err
+	}
+	return s.flows, nil
+}
+
+// AccruedInterest returns the interest accrued since the last coupon date till settlement.
+// The result is rounded to prec decimal places with given rounding mode.
+func (b Bond) AccruedInterest(settlement time.Time, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, err
+	}
+	return b.accrued(s, settlement, prec, mode)
+}
+
+// DirtyPrice returns the price including accrued interest for given annual yield, e.g. 0.05 for 5%.
+// The yield is compounded Frequency times per year.
+// The result is rounded to prec decimal places with given rounding mode.
+func (b Bond) DirtyPrice(settlement time.Time, yield dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, err
+	}
+	return b.pv(s, yield).price.RoundTo(prec, mode), nil
+}
+
+// CleanPrice returns the price excluding accrued interest for given annual yield.
+// The result is rounded to prec decimal places with given rounding mode.
+// An error is returned, if the price is less, than the accrued interest.
+func (b Bond) CleanPrice(settlement time.Time, yield dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, err
+	}
+	accrued, err := b.accrued(s, settlement, workPrec, dfp.RoundNearest)
+	if err != nil {
+		return zero, err
+	}
+	clean, neg := b.pv(s, yield).price.Sub(accrued)
+	if neg {
+		return zero, fmt.Errorf("price is less than accrued interest")
+	}
+	return clean.RoundTo(prec, mode), nil
+}
+
+// Yield returns the yield to maturity for given clean price using Newton's method.
+// The result is rounded to prec decimal places with given rounding mode.
+func (b Bond) Yield(settlement time.Time, cleanPrice dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, err
+	}
+	accrued, err := b.accrued(s, settlement, workPrec, dfp.RoundNearest)
+	if err != nil {
+		return zero, err
+	}
+	target := cleanPrice.Add(accrued)
+	// the precision yield is calculated with should be greater, than the requested one.
+	eps := dfp.FromMantAndExp(1, -int32(prec+3))
+	y := b.Coupon
+	if y.IsZero() {
+		y = dfp.MustFromString("0.05")
+	}
+	for i := 0; i < maxIterations; i++ {
+		r := b.pv(s, y)
+		// P(y) decreases as y grows, so y(n+1) = y(n) + (P(y) - target) / |P'(y)|.
+		diff, neg := r.price.Sub(target)
+		step := diff.Quo(r.derivative, workPrec, dfp.RoundNearest)
+		if step.Cmp(eps) < 0 {
+			return y.RoundTo(prec, mode), nil
+		}
+		if !neg {
+			y = y.Add(step)
+		} else if next, neg := y.Sub(step); !neg {
+			y = next
+		} else { // yields can not be negative, so go halfway to zero.
+			y = y.Quo(two, workPrec, dfp.RoundNearest)
+		}
+	}
+	return zero, fmt.Errorf("yield did not converge")
+}
+
+// Duration returns Macaulay and modified durations in years for given yield.
+// The results are rounded to prec decimal places with given rounding mode.
+func (b Bond) Duration(settlement time.Time, yield dfp.Value, prec int, mode dfp.RoundingMode) (macaulay, modified dfp.Value, err error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, zero, err
+	}
+	r := b.pv(s, yield)
+	macaulay = r.weighted.Quo(r.price.Mul(b.frequency()), workPrec, dfp.RoundNearest)
+	modified = macaulay.Quo(r.x, workPrec, dfp.RoundNearest)
+	return macaulay.RoundTo(prec, mode), modified.RoundTo(prec, mode), nil
+}
+
+// Convexity returns the convexity in years squared for given yield.
+// The result is rounded to prec decimal places with given rounding mode.
+func (b Bond) Convexity(settlement time.Time, yield dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	s, err := b.schedule(settlement)
+	if err != nil {
+		return zero, err
+	}
+	r := b.pv(s, yield)
+	f := b.frequency()
+	den := r.price.Mul(r.x).Mul(r.x).Mul(f).Mul(f)
+	return r.convexity.Quo(den, workPrec, dfp.RoundNearest).RoundTo(prec, mode), nil
+}
+
+func (b Bond) frequency() dfp.Value {
+	return dfp.FromUint64(uint64(b.Frequency))
+}
+
+func (b Bond) schedule(settlement time.Time) (schedule, error) {
+	if b.Frequency <= 0 || 12%b.Frequency != 0 {
+		return schedule{}, fmt.Errorf("bad coupon frequency: %d", b.Frequency)
+	}
+	if b.Face.IsZero() {
+		return schedule{}, fmt.Errorf("face value is not positive")
+	}
+	if !settlement.Before(b.Maturity) {
+		return schedule{}, fmt.Errorf("settlement is not before maturity")
+	}
+	step := 12 / b.Frequency
+	// coupon dates are calculated backwards from the maturity date.
+	n := 1
+	for daycount.AddMonths(b.Maturity, -n*step).After(settlement) {
+		n++
+	}
+	s := schedule{
+		prev:  daycount.AddMonths(b.Maturity, -n*step),
+		next:  daycount.AddMonths(b.Maturity, -(n-1)*step),
+		flows: make([]CashFlow, 0, n),
+	}
+	coupon := b.Face.Mul(b.Coupon).Quo(b.frequency(), workPrec, dfp.RoundNearest)
+	for k := n - 1; k >= 0; k-- {
+		cf := CashFlow{Date: daycount.AddMonths(b.Maturity, -k*step), Amount: coupon}
+		if k == 0 {
+			cf.Amount = cf.Amount.Add(b.Face)
+		}
+		s.flows = append(s.flows, cf)
+	}
+	s.w = dfp.FromUint64(days(settlement, s.next)).Quo(dfp.FromUint64(days(s.prev, s.next)), workPrec, dfp.RoundNearest)
+	return s, nil
+}
+
+func (b Bond) accrued(s schedule, settlement time.Time, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	c := b.DayCount
+	if c == nil {
+		c = daycount.ActActICMA{Frequency: b.Frequency, PeriodStart: s.prev, PeriodEnd: s.next}
+	}
+	return daycount.AccruedInterest(b.Face, b.Coupon, s.prev, settlement, c, prec, mode)
+}
+
+// pvResult holds the sums, that are used to calculate prices and risk measures.
+type pvResult struct {
+	// x = 1 + y/f.
+	x dfp.Value
+	// price = sum(CF(k) / x^t(k)), where t(k) = k + w is the time in periods.
+	price dfp.Value
+	// derivative = |dPrice/dy| = sum(CF(k) * t(k) / x^(t(k)+1)) / f.
+	derivative dfp.Value
+	// weighted = sum(CF(k) * t(k) / x^t(k)).
+	weighted dfp.Value
+	// convexity = sum(CF(k) * t(k) * (t(k)+1) / x^t(k)).
+	convexity dfp.Value
+}
+
+func (b Bond) pv(s schedule, yield dfp.Value) pvResult {
+	f := b.frequency()
+	r := pvResult{x: one.Add(yield.Quo(f, workPrec, dfp.RoundNearest))}
+	inv := one.Quo(r.x, workPrec, dfp.RoundNearest)
+	df := one.Quo(pow(r.x, s.w), workPrec, dfp.RoundNearest)
+	r.price, r.weighted, r.convexity = zero, zero, zero
+	for k, cf := range s.flows {
+		t := dfp.FromUint64(uint64(k)).Add(s.w)
+		pv := cf.Amount.Mul(df)
+		r.price = r.price.Add(pv)
+		r.weighted = r.weighted.Add(pv.Mul(t))
+		r.convexity = r.convexity.Add(pv.Mul(t).Mul(t.Add(one)))
+		df = df.Mul(inv)
+	}
+	r.derivative = r.weighted.Quo(r.x.Mul(f), workPrec, dfp.RoundNearest)
+	return r
+}
+
+// pow calculates x^p for x >= 1 as exp(p * ln(x)).
+func pow(x, p dfp.Value) dfp.Value {
+	if p.IsZero() {
+		return one
+	}
+	return exp(p.Mul(ln(x)))
+}
+
+// ln calculates the natural logarithm for x >= 1 using the series
+// ln(x) = 2 * sum(t^(2k+1) / (2k+1)), where t = (x-1)/(x+1).
+func ln(x dfp.Value) dfp.Value {
+	num, _ := x.Sub(one)
+	t := num.Quo(x.Add(one), workPrec, dfp.RoundNearest)
+	t2 := t.Mul(t)
+	sum, term := zero, t
+	for k := uint64(1); k < 2*maxIterations; k += 2 {
+		next := sum.Add(term.Quo(dfp.FromUint64(k), workPrec, dfp.RoundNearest))
+		if next.Eq(sum) {
+			break
+		}
+		sum, term = next, term.Mul(t2)
+	}
+	return sum.Mul(two)
+}
+
+// exp calculates e^x for x >= 0 using the series exp(x) = sum(x^k / k!).
+func exp(x dfp.Value) dfp.Value {
+	sum, term := one, one
+	for k := uint64(1); k < maxIterations; k++ {
+		term = term.Mul(x).Quo(dfp.FromUint64(k), workPrec, dfp.RoundNearest)
+		next := sum.Add(term)
+		if next.Eq(sum) {
+			break
+		}
+		sum = next
+	}
+	return sum
+}
+
+func days(start, end time.Time) uint64 {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	return uint64(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package bond
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func d(y int, m time.Month, day int) time.Time {
+	return time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCashFlows(t *testing.T) {
+	a := assert.New(t)
+	b := Bond{Face: dfp.MustFromString("100"), Coupon: dfp.MustFromString("0.05"), Frequency: 2, Maturity: d(2021, 8, 31)}
+	flows, err := b.CashFlows(d(2020, 3, 15))
+	if !a.NoError(err) || !a.Len(flows, 3) {
+		return
+	}
+	expected := []CashFlow{
+		{d(2020, 8, 31), dfp.MustFromString("2.5")},
+		{d(2021, 2, 28), dfp.MustFromString("2.5")},
+		{d(2021, 8, 31), dfp.MustFromString("102.5")},
+	}
+	for i, cf := range flows {
+		a.Equal(expected[i].Date, cf.Date)
+		a.True(expected[i].Amount.Eq(cf.Amount), "%s != %s", expected[i].Amount, cf.Amount)
+	}
+	_, err = b.CashFlows(d(2021, 8, 31))
+	a.EqualError(err, "settlement is not before maturity")
+	b.Frequency = 5
+	_, err = b.CashFlows(d(2020, 3, 15))
+	a.EqualError(err, "bad coupon frequency: 5")
+	b.Frequency = 2
+	b.Face = dfp.MustFromString("0")
+	_, err = b.CashFlows(d(2020, 3, 15))
+	a.EqualError(err, "face value is not positive")
+	_, err = b.Yield(d(2020, 3, 15), dfp.MustFromString("100"), 6, dfp.RoundHalfUp)
+	a.EqualError(err, "face value is not positive")
+	_, _, err = b.Duration(d(2020, 3, 15), dfp.MustFromString("0.05"), 6, dfp.RoundHalfUp)
+	a.EqualError(err, "face value is not positive")
+	_, err = b.Convexity(d(2020, 3, 15), dfp.MustFromString("0.05"), 6, dfp.RoundHalfUp)
+	a.EqualError(err, "face value is not positive")
+}
+
+func TestAnalytics(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		b          Bond
+		settlement time.Time
+		yield      string
+
+		accrued, dirty, clean         string
+		macaulay, modified, convexity string
+	}{
+		{
+			b: Bond{
+				Face: dfp.MustFromString("100"), Coupon: dfp.MustFromString("0.05"),
+				Frequency: 2, Maturity: d(2030, 1, 15),
+			},
+			settlement: d(2020, 1, 15), yield: "0.05",
+			accrued: "0", dirty: "100", clean: "100",
+			macaulay: "7.989446", modified: "7.794581", convexity: "73.628731",
+		},
+		{
+			b: Bond{
+				Face: dfp.MustFromString("100"), Coupon: dfp.MustFromString("0.045"),
+				Frequency: 2, Maturity: d(2030, 5, 15),
+			},
+			settlement: d(2020, 3, 3), yield: "0.0525",
+			accrued: "1.347527", dirty: "95.475054", clean: "94.127526",
+			macaulay: "8.10643", modified: "7.899079", convexity: "76.243437",
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			const prec = 6
+			mode := dfp.RoundHalfUp
+			y := dfp.MustFromString(test.yield)
+			accrued, err := test.b.AccruedInterest(test.settlement, prec, mode)
+			if a.NoError(err) {
+				a.Equal(test.accrued, accrued.String())
+			}
+			dirty, err := test.b.DirtyPrice(test.settlement, y, prec, mode)
+			if a.NoError(err) {
+				a.Equal(test.dirty, dirty.String())
+			}
+			clean, err := test.b.CleanPrice(test.settlement, y, prec, mode)
+			if a.NoError(err) {
+				a.Equal(test.clean, clean.String())
+			}
+			mac, mod, err := test.b.Duration(test.settlement, y, prec, mode)
+			if a.NoError(err) {
+				a.Equal(test.macaulay, mac.String())
+				a.Equal(test.modified, mod.String())
+			}
+			conv, err := test.b.Convexity(test.settlement, y, prec, mode)
+			if a.NoError(err) {
+				a.Equal(test.convexity, conv.String())
+			}
+			clean, err = test.b.CleanPrice(test.settlement, y, 12, mode)
+			if a.NoError(err) {
+				yield, err := test.b.Yield(test.settlement, clean, 8, mode)
+				if a.NoError(err) {
+					a.True(y.Eq(yield), "%s != %s", test.yield, yield)
+				}
+			}
+		})
+	}
+}
+
+func TestYield(t *testing.T) {
+	a := assert.New(t)
+	b := Bond{Face: dfp.MustFromString("100"), Coupon: dfp.MustFromString("0.06"), Frequency: 1, Maturity: d(2025, 6, 1)}
+	settlement := d(2020, 6, 1)
+	tests := []struct {
+		price, yield string
+	}{
+		{"100", "0.06"},
+		{"104.329477", "0.05"},
+		{"130", "0"},
+		{"70", "0.149359"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			y, err := b.Yield(settlement, dfp.MustFromString(test.price), 6, dfp.RoundHalfUp)
+			if a.NoError(err) {
+				a.Equal(test.yield, y.String())
+			}
+		})
+	}
+}
+
+func TestLnExp(t *testing.T) {
+	a := assert.New(t)
+	eps := dfp.MustFromString("1e-14")
+	tests := []struct {
+		actual   dfp.Value
+		expected string
+	}{
+		{ln(two), "0.6931471805599453"},
+		{ln(dfp.MustFromString("1.025")), "0.02469261259037141"},
+		{exp(one), "2.718281828459045"},
+		{exp(dfp.MustFromString("0.01")), "1.010050167084168"},
+		{pow(two, dfp.MustFromString("0.5")), "1.414213562373095"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			diff, _ := test.actual.Sub(dfp.MustFromString(test.expected))
+			a.True(diff.Cmp(eps) < 0, "%s != %s", test.actual, test.expected)
+		})
+	}
+}
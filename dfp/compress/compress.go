@@ -0,0 +1,177 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package compress implements a streaming codec for sequences of dfp.Value, like tick prices.
+//
+// Consecutive prices usually share an exponent and differ by a few ticks, so the codec keeps a current exponent,
+// and writes only the delta-of-delta of mantissas as a zigzag varint. Values are rescaled to the current exponent
+// when possible, so 1.25 and 1.3 are both written with exponent -2. The stream starts with a version byte.
+// Every value starts with a control varint: if its lowest bit is 0, the rest is a delta-of-delta of the mantissa,
+// otherwise the rest is a zigzag delta of the exponent, and it is followed by a varint mantissa.
+//
+// Read returns values, which are equal to the written ones, but they may have different mantissas and exponents.
+package compress
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	version = 1
+)
+
+var (
+	minExponent = int64(dfp.Min.Exp())
+	maxExponent = int64(dfp.Max.Exp())
+	maxMantissa = dfp.Max.MantUint64()
+)
+
+// state is a state of the codec, that is the same for a writer and a reader.
+type state struct {
+	started bool
+	exp     int32
+	mant    uint64
+	delta   int64
+}
+
+// Writer encodes values into an underlying writer.
+// Written data is buffered, so Flush must be called after the last value.
+type Writer struct {
+	state
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewWriter returns a new writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write encodes a value.
+func (w *Writer) Write(v dfp.Value) error {
+	if !w.started {
+		if err := w.w.WriteByte(version); err != nil {
+			return err
+		}
+		w.started = true
+	}
+	v = v.Normalized()
+	m, e := v.MantUint64(), v.Exp()
+	if rescaled, ok := rescale(m, e, w.exp); ok {
+		delta := int64(rescaled) - int64(w.mant)
+		if err := w.writeUvarint(zigzag(delta-w.delta) << 1); err != nil {
+			return err
+		}
+		w.mant, w.delta = rescaled, delta
+		return nil
+	}
+	if err := w.writeUvarint(zigzag(int64(e-w.exp))<<1 | 1); err != nil {
+		return err
+	}
+	if err := w.writeUvarint(m); err != nil {
+		return err
+	}
+	w.exp, w.mant, w.delta = e, m, 0
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func (w *Writer) writeUvarint(x uint64) error {
+	n := binary.PutUvarint(w.buf[:], x)
+	_, err := w.w.Write(w.buf[:n])
+	return err
+}
+
+// Reader decodes values from an underlying reader.
+type Reader struct {
+	state
+	r io.ByteReader
+}
+
+// NewReader returns a new reader.
+// If r does not implement io.ByteReader, the data is read through a bufio.Reader.
+func NewReader(r io.Reader) *Reader {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{r: br}
+}
+
+// Read decodes the next value. It returns io.EOF, if there are no more values,
+// and io.ErrUnexpectedEOF, if the stream ends in the middle of a value.
+func (r *Reader) Read() (dfp.Value, error) {
+	if !r.started {
+		ver, err := r.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if ver != version {
+			return 0, fmt.Errorf("unsupported version %d", ver)
+		}
+		r.started = true
+	}
+	ctrl, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, err
+	}
+	if ctrl&1 == 0 {
+		delta := r.delta + unzigzag(ctrl>>1)
+		mant := int64(r.mant) + delta
+		if mant < 0 || uint64(mant) > maxMantissa {
+			return 0, fmt.Errorf("corrupted data: mantissa is out of range")
+		}
+		r.mant, r.delta = uint64(mant), delta
+	} else {
+		exp := int64(r.exp) + unzigzag(ctrl>>1)
+		if exp < minExponent || exp > maxExponent {
+			return 0, fmt.Errorf("corrupted data: exponent is out of range")
+		}
+		mant, err := binary.ReadUvarint(r.r)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if mant > maxMantissa {
+			return 0, fmt.Errorf("corrupted data: mantissa is out of range")
+		}
+		r.exp, r.mant, r.delta = int32(exp), mant, 0
+	}
+	return dfp.FromMantAndExp(r.mant, r.exp), nil
+}
+
+// rescale returns the mantissa for m*10^e with exponent exp, if it can be represented precisely.
+func rescale(m uint64, e, exp int32) (uint64, bool) {
+	if m == 0 {
+		return 0, true
+	}
+	if e < exp {
+		return 0, false
+	}
+	// m is not zero, so the loop stops as soon as m overflows the mantissa, even if e - exp is large.
+	for ; e > exp; e-- {
+		if m > maxMantissa/10 {
+			return 0, false
+		}
+		m *= 10
+	}
+	return m, true
+}
+
+func zigzag(x int64) uint64 {
+	return uint64(x<<1) ^ uint64(x>>63)
+}
+
+func unzigzag(x uint64) int64 {
+	return int64(x>>1) ^ -int64(x&1)
+}
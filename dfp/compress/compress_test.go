@@ -0,0 +1,145 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package compress
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+// ticks returns a random walk of prices with a tick size of 10^exp.
+func ticks(rnd *rand.Rand, n int, start uint64, exp int32) []dfp.Value {
+	values := make([]dfp.Value, n)
+	m := start
+	for i := range values {
+		m = uint64(int64(m) + int64(rnd.Intn(7)-3))
+		values[i] = dfp.FromMantAndExp(m, exp)
+	}
+	return values
+}
+
+func encode(t testing.TB, values []dfp.Value) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, v := range values {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	random := make([]dfp.Value, 1000)
+	for i := range random {
+		random[i] = dfp.FromMantAndExp(uint64(rnd.Int63n(1<<56))>>uint(rnd.Intn(56)), int32(rnd.Intn(256)-127))
+	}
+	tests := [][]dfp.Value{
+		nil,
+		{dfp.Value(0)},
+		{dfp.Min, dfp.Max, dfp.Value(0), dfp.Max, dfp.Min},
+		{dfp.MustFromString("1.25"), dfp.MustFromString("1.3"), dfp.MustFromString("1.300"), dfp.MustFromString("1.2")},
+		{dfp.MustFromString("1e10"), dfp.MustFromString("1e-10"), dfp.MustFromString("1e10")},
+		ticks(rnd, 1000, 110250, -5),
+		random,
+	}
+	for i, values := range tests {
+		data := encode(t, values)
+		r := NewReader(bytes.NewReader(data))
+		for j, expected := range values {
+			v, err := r.Read()
+			if !a.NoError(err, "%d: %d", i, j) || !a.True(expected.Eq(v), "%d: %d: %v != %v", i, j, expected, v) {
+				break
+			}
+		}
+		_, err := r.Read()
+		a.Equal(io.EOF, err, "%d", i)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(1))
+	values := ticks(rnd, 10000, 110250, -5)
+	data := encode(t, values)
+	// every tick takes a single byte.
+	a.True(len(data) < len(values)+10, "%d bytes", len(data))
+
+	values = []dfp.Value{dfp.MustFromString("1.25"), dfp.MustFromString("1.3"), dfp.MustFromString("1.35")}
+	a.Equal([]byte{version, 3<<1 | 1, 125, 5 << 2, 0}, encode(t, values))
+}
+
+func TestRescale(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		m      uint64
+		e, exp int32
+		result uint64
+		ok     bool
+	}{
+		{0, 5, -100, 0, true},
+		{15, -1, -1, 15, true},
+		{15, -1, -3, 1500, true},
+		{15, 0, 1, 0, false},
+		{1, 16, 0, 10000000000000000, true},
+		{1, 17, 0, 0, false},
+		{maxMantissa, 1, 0, 0, false},
+		{1, 100, -100, 0, false},
+	}
+	for _, test := range tests {
+		result, ok := rescale(test.m, test.e, test.exp)
+		a.Equal(test.ok, ok, "%d %d %d", test.m, test.e, test.exp)
+		a.Equal(test.result, result, "%d %d %d", test.m, test.e, test.exp)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewReader(bytes.NewReader([]byte{2})).Read()
+	a.EqualError(err, "unsupported version 2")
+
+	data := encode(t, []dfp.Value{dfp.MustFromString("1.25")})
+	_, err = NewReader(bytes.NewReader(data[:len(data)-1])).Read()
+	a.Equal(io.ErrUnexpectedEOF, err)
+
+	_, err = NewReader(bytes.NewReader([]byte{version, 2})).Read()
+	a.EqualError(err, "corrupted data: mantissa is out of range")
+	_, err = NewReader(bytes.NewReader([]byte{version, 0xa1, 0x06})).Read()
+	a.EqualError(err, "corrupted data: exponent is out of range")
+}
+
+func BenchmarkWriter(b *testing.B) {
+	values := ticks(rand.New(rand.NewSource(1)), 100000, 110250, -5)
+	b.ResetTimer()
+	var size int
+	for i := 0; i < b.N; i++ {
+		size = len(encode(b, values))
+	}
+	b.ReportMetric(float64(size)/float64(len(values)), "bytes/value")
+	b.ReportMetric(float64(8*len(values))/float64(size), "ratio")
+}
+
+func BenchmarkReader(b *testing.B) {
+	data := encode(b, ticks(rand.New(rand.NewSource(1)), 100000, 110250, -5))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := NewReader(bytes.NewReader(data))
+		for {
+			if _, err := r.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package daycount implements day count conventions, which are used to calculate accrued interest.
+// Year fractions are calculated as exact ratios of integers and are converted to decimal values
+// with an explicit precision and rounding mode.
+package daycount
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const secondsInDay = 24 * 60 * 60
+
+var (
+	// Act360 is ACT/360: actual number of days divided by 360.
+	Act360 Convention = actFixed(360)
+	// Act365F is ACT/365 Fixed: actual number of days divided by 365.
+	Act365F Convention = actFixed(365)
+	// ActActISDA is ACT/ACT ISDA: days in leap years are divided by 366, days in other years by 365.
+	ActActISDA Convention = actActISDA{}
+	// Thirty360US is 30/360 US, also known as the bond basis, with the end of February adjustments.
+	Thirty360US Convention = thirty360US{}
+	// Thirty360EU is 30E/360, also known as the Eurobond basis.
+	Thirty360EU Convention = thirty360EU{}
+)
+
+// Convention calculates the fraction of a year between two dates.
+type Convention interface {
+	// Fraction returns the year fraction between start and end as num/den.
+	// end must not be before start.
+	Fraction(start, end time.Time) (num, den uint64)
+}
+
+// ActActICMA is ACT/ACT ICMA: actual number of days divided by the number of days in the coupon period
+// multiplied by the number of coupon periods in a year.
+type ActActICMA struct {
+	// Frequency is the number of coupon periods per year.
+	Frequency int
+	// PeriodStart and PeriodEnd define the reference coupon period.
+	PeriodStart, PeriodEnd time.Time
+}
+
+// Fraction implements Convention.
+func (c ActActICMA) Fraction(start, end time.Time) (num, den uint64) {
+	return uint64(days(start, end)), uint64(c.Frequency) * uint64(days(c.PeriodStart, c.PeriodEnd))
+}
+
+// Thirty360ISDA is 30E/360 ISDA: the last day of a month is considered to be the 30th,
+// except for the maturity date in February.
+type Thirty360ISDA struct {
+	// Maturity is the termination date of the instrument.
+	Maturity time.Time
+}
+
+// Fraction implements Convention.
+func (c Thirty360ISDA) Fraction(start, end time.Time) (num, den uint64) {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	if isLastDayOfMonth(start) {
+		d1 = 30
+	}
+	if isLastDayOfMonth(end) && !(m2 == time.February && sameDate(end, c.Maturity)) {
+		d2 = 30
+	}
+	return thirty360(y1, m1, d1, y2, m2, d2), 360
+}
+
+// Bus252 is BUS/252: the number of business days divided by 252.
+type Bus252 struct {
+	// IsHoliday reports whether a weekday is a holiday. Weekends are never business days.
+	// If nil, all weekdays are business days.
+	IsHoliday func(t time.Time) bool
+}
+
+// Fraction implements Convention.
+func (c Bus252) Fraction(start, end time.Time) (num, den uint64) {
+	for d := date(start); d.Before(date(end)); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		if c.IsHoliday != nil && c.IsHoliday(d) {
+			continue
+		}
+		num++
+	}
+	return num, 252
+}
+
+// YearFraction returns the year fraction between start and end according to the convention.
+// The result is rounded to prec decimal places with given rounding mode.
+func YearFraction(c Convention, start, end time.Time, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	num, den, err := fraction(c, start, end)
+	if err != nil {
+		return dfp.Value(0), err
+	}
+	return dfp.FromUint64(num).Quo(dfp.FromUint64(den), prec, mode), nil
+}
+
+// AccruedInterest returns the interest accrued on notional between start and end, that is
+// notional * rate * yearFraction(start, end). The rate is annual, e.g. 0.05 for 5%.
+// The result is rounded to prec decimal places with given rounding mode.
+func AccruedInterest(notional, rate dfp.Value, start, end time.Time, c Convention, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	num, den, err := fraction(c, start, end)
+	if err != nil {
+		return dfp.Value(0), err
+	}
+	return notional.Mul(rate).Mul(dfp.FromUint64(num)).Quo(dfp.FromUint64(den), prec, mode), nil
+}
+
+func fraction(c Convention, start, end time.Time) (num, den uint64, err error) {
+	if date(end).Before(date(start)) {
+		return 0, 0, fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+	if num, den = c.Fraction(start, end); den == 0 {
+		return 0, 0, fmt.Errorf("bad convention parameters")
+	}
+	return num, den, nil
+}
+
+type actFixed uint64
+
+func (c actFixed) Fraction(start, end time.Time) (num, den uint64) {
+	return uint64(days(start, end)), uint64(c)
+}
+
+type actActISDA struct{}
+
+func (actActISDA) Fraction(start, end time.Time) (num, den uint64) {
+	start, end = date(start), date(end)
+	var leap, nonLeap int64
+	for start.Before(end) {
+		next := time.Date(start.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if next.After(end) {
+			next = end
+		}
+		if isLeap(start.Year()) {
+			leap += days(start, next)
+		} else {
+			nonLeap += days(start, next)
+		}
+		start = next
+	}
+	// leap/366 + nonLeap/365
+	num, den = uint64(leap*365+nonLeap*366), 365*366
+	d := gcd(num, den)
+	return num / d, den / d
+}
+
+type thirty360US struct{}
+
+func (thirty360US) Fraction(start, end time.Time) (num, den uint64) {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	if isLastDayOfFebruary(start) {
+		if isLastDayOfFebruary(end) {
+			d2 = 30
+		}
+		d1 = 30
+	}
+	if d2 == 31 && d1 >= 30 {
+		d2 = 30
+	}
+	if d1 == 31 {
+		d1 = 30
+	}
+	return thirty360(y1, m1, d1, y2, m2, d2), 360
+}
+
+type thirty360EU struct{}
+
+func (thirty360EU) Fraction(start, end time.Time) (num, den uint64) {
+	y1, m1, d1 := start.Date()
+	y2, m2, d2 := end.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 {
+		d2 = 30
+	}
+	return thirty360(y1, m1, d1, y2, m2, d2), 360
+}
+
+func thirty360(y1 int, m1 time.Month, d1, y2 int, m2 time.Month, d2 int) uint64 {
+	result := 360*(y2-y1) + 30*int(m2-m1) + d2 - d1
+	if result < 0 { // may happen, if both dates are in the same month.
+		return 0
+	}
+	return uint64(result)
+}
+
+// AddMonths adds given number of months to t.
+// Unlike time.AddDate, it does not overflow into the next month, but sticks to the last day of the month,
+// so that adding one month to Jan 31 gives Feb 28 (or 29).
+func AddMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// date drops time and location from t.
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// days returns the number of days between two dates.
+func days(start, end time.Time) int64 {
+	return (date(end).Unix() - date(start).Unix()) / secondsInDay
+}
+
+func sameDate(a, b time.Time) bool {
+	return date(a).Equal(date(b))
+}
+
+func isLastDayOfMonth(t time.Time) bool {
+	return t.AddDate(0, 0, 1).Day() == 1
+}
+
+func isLastDayOfFebruary(t time.Time) bool {
+	return t.Month() == time.February && isLastDayOfMonth(t)
+}
+
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
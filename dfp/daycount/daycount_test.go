@@ -0,0 +1,106 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package daycount
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func d(y int, m time.Month, day int) time.Time {
+	return time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestYearFraction(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		c          Convention
+		start, end time.Time
+		expected   string
+	}{
+		{Act360, d(2007, 12, 28), d(2008, 2, 28), "0.1722222222"},
+		{Act365F, d(2007, 12, 28), d(2008, 2, 28), "0.1698630137"},
+		{ActActISDA, d(2007, 12, 28), d(2008, 2, 28), "0.1694288495"},
+		{ActActISDA, d(2007, 12, 28), d(2009, 2, 28), "1.1698630137"},
+		{ActActISDA, d(2008, 1, 1), d(2008, 1, 1), "0"},
+		{Thirty360US, d(2007, 12, 28), d(2008, 2, 28), "0.1666666667"},
+		{Thirty360EU, d(2007, 12, 28), d(2008, 2, 28), "0.1666666667"},
+		{Thirty360ISDA{Maturity: d(2010, 2, 28)}, d(2007, 12, 28), d(2008, 2, 28), "0.1666666667"},
+
+		{Thirty360US, d(2008, 2, 29), d(2009, 2, 28), "1"},
+		{Thirty360EU, d(2008, 2, 29), d(2009, 2, 28), "0.9972222222"},
+		{Thirty360ISDA{Maturity: d(2010, 2, 28)}, d(2008, 2, 29), d(2009, 2, 28), "1"},
+		{Thirty360ISDA{Maturity: d(2009, 2, 28)}, d(2008, 2, 29), d(2009, 2, 28), "0.9944444444"},
+
+		{Thirty360US, d(2007, 10, 31), d(2008, 11, 30), "1.0833333333"},
+		{Thirty360EU, d(2007, 10, 31), d(2008, 11, 30), "1.0833333333"},
+		{Thirty360US, d(2008, 2, 1), d(2009, 5, 31), "1.3333333333"},
+		{Thirty360EU, d(2008, 2, 1), d(2009, 5, 31), "1.3305555556"},
+
+		{ActActICMA{Frequency: 2, PeriodStart: d(2020, 1, 15), PeriodEnd: d(2020, 7, 15)}, d(2020, 1, 15), d(2020, 3, 15), "0.1648351648"},
+		{Bus252{}, d(2020, 1, 6), d(2020, 1, 13), "0.0198412698"},
+		{
+			Bus252{IsHoliday: func(t time.Time) bool { return t.Equal(d(2020, 1, 8)) }},
+			d(2020, 1, 6), d(2020, 1, 13), "0.0158730159",
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := YearFraction(test.c, test.start, test.end, 10, dfp.RoundHalfUp)
+			if a.NoError(err) {
+				a.Equal(test.expected, v.String())
+			}
+		})
+	}
+}
+
+func TestAccruedInterest(t *testing.T) {
+	a := assert.New(t)
+	notional, rate := dfp.MustFromString("1000000"), dfp.MustFromString("0.05")
+	v, err := AccruedInterest(notional, rate, d(2020, 1, 1), d(2020, 4, 1), Act360, 2, dfp.RoundHalfUp)
+	if a.NoError(err) {
+		a.Equal("12638.89", v.String())
+	}
+	v, err = AccruedInterest(notional, rate, d(2020, 1, 1), d(2020, 4, 1), Act365F, 2, dfp.RoundHalfUp)
+	if a.NoError(err) {
+		a.Equal("12465.75", v.String())
+	}
+	v, err = AccruedInterest(notional, rate, d(2020, 1, 1), d(2020, 4, 1), Act365F, 2, dfp.RoundDown)
+	if a.NoError(err) {
+		a.Equal("12465.75", v.String())
+	}
+	v, err = AccruedInterest(notional, rate, d(2020, 1, 1), d(2020, 4, 1), Act365F, 0, dfp.RoundUp)
+	if a.NoError(err) {
+		a.Equal("12466", v.String())
+	}
+	_, err = AccruedInterest(notional, rate, d(2020, 4, 1), d(2020, 1, 1), Act360, 2, dfp.RoundHalfUp)
+	a.EqualError(err, "end date 2020-01-01 is before start date 2020-04-01")
+	_, err = YearFraction(ActActICMA{Frequency: 2}, d(2020, 1, 1), d(2020, 4, 1), 2, dfp.RoundHalfUp)
+	a.EqualError(err, "bad convention parameters")
+}
+
+func TestAddMonths(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		t        time.Time
+		months   int
+		expected time.Time
+	}{
+		{d(2020, 1, 15), 1, d(2020, 2, 15)},
+		{d(2020, 1, 31), 1, d(2020, 2, 29)},
+		{d(2021, 1, 31), 1, d(2021, 2, 28)},
+		{d(2020, 8, 31), 6, d(2021, 2, 28)},
+		{d(2020, 12, 31), 12, d(2021, 12, 31)},
+		{d(2020, 3, 31), -1, d(2020, 2, 29)},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			a.Equal(test.expected, AddMonths(test.t, test.months))
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+)
+
+// IEEE 754-2008 decimal64 format constants.
+const (
+	d64Bias        = 398
+	d64MaxCoeff    = 9999999999999999
+	d64Digits      = 16
+	d64SignMask    = 1 << 63
+	d64SpecialMask = 0x1f << 58
+	d64Inf         = 0x1e << 58
+	d64NaN         = 0x1f << 58
+
+	// BID: if the two bits after the sign are 11, the exponent is shifted by two bits,
+	// and the coefficient has an implicit 100 prefix.
+	bidLargeMask     = 3 << 61
+	bidSmallCoeffLen = 53
+	bidLargeCoeffLen = 51
+
+	// DPD: 5 bits of the combination field, 8 bits of exponent continuation, and 5 10-bit declets.
+	dpdExpContLen  = 8
+	dpdCoeffLen    = 50
+	dpdDecletLen   = 10
+	dpdDecletCount = 5
+)
+
+var (
+	// dpdEncodeTable maps a number in [0, 999] into a canonical declet.
+	dpdEncodeTable [1000]uint16
+	// dpdDecodeTable maps a declet into a number in [0, 999].
+	dpdDecodeTable [1024]uint16
+)
+
+func init() {
+	filled := make([]bool, len(dpdEncodeTable))
+	for d := range dpdDecodeTable {
+		n := decodeDeclet(uint16(d))
+		dpdDecodeTable[d] = n
+		// non-canonical declets are greater, than the canonical ones, so the first match is the canonical one.
+		if !filled[n] {
+			dpdEncodeTable[n] = uint16(d)
+			filled[n] = true
+		}
+	}
+}
+
+// ToDecimal64BID returns an IEEE 754-2008 decimal64 number in Binary Integer Decimal encoding.
+// Returns false, if the least significant digits of the mantissa were truncated.
+func (v Value) ToDecimal64BID() (d uint64, exact bool) {
+	c, e, exact := toDecimal64(v)
+	if c>>bidSmallCoeffLen == 0 {
+		return e<<bidSmallCoeffLen | c, exact
+	}
+	return bidLargeMask | e<<bidLargeCoeffLen | c&(1<<bidLargeCoeffLen-1), exact
+}
+
+// ToDecimal64DPD returns an IEEE 754-2008 decimal64 number in Densely Packed Decimal encoding.
+// Returns false, if the least significant digits of the mantissa were truncated.
+func (v Value) ToDecimal64DPD() (d uint64, exact bool) {
+	c, e, exact := toDecimal64(v)
+	lead := c / pow10(d64Digits-1)
+	c %= pow10(d64Digits - 1)
+	var comb uint64
+	if lead < 8 {
+		comb = (e>>dpdExpContLen)<<3 | lead
+	} else {
+		comb = 0x18 | (e>>dpdExpContLen)<<1 | lead&1
+	}
+	var cont uint64
+	for i := 0; i < dpdDecletCount; i++ {
+		cont |= uint64(dpdEncodeTable[c%1000]) << (i * dpdDecletLen)
+		c /= 1000
+	}
+	return comb<<(dpdExpContLen+dpdCoeffLen) | (e&(1<<dpdExpContLen-1))<<dpdCoeffLen | cont, exact
+}
+
+// FromDecimal64BID returns a value for an IEEE 754-2008 decimal64 number in Binary Integer Decimal encoding.
+// Returns an error for negative numbers, infinities, and not-a-numbers.
+// Returns false, if the number is out of range and can not be represented precisely.
+func FromDecimal64BID(d uint64) (v Value, exact bool, err error) {
+	if err := checkDecimal64(d); err != nil {
+		return zero, false, err
+	}
+	var c, e uint64
+	if d&bidLargeMask == bidLargeMask {
+		e = d >> bidLargeCoeffLen & (1<<(dpdExpContLen+2) - 1)
+		c = 1<<bidSmallCoeffLen | d&(1<<bidLargeCoeffLen-1)
+	} else {
+		e = d >> bidSmallCoeffLen & (1<<(dpdExpContLen+2) - 1)
+		c = d & (1<<bidSmallCoeffLen - 1)
+	}
+	if c > d64MaxCoeff { // non-canonical coefficients are treated as zeros.
+		c = 0
+	}
+	if d&d64SignMask != 0 && c != 0 {
+		return zero, false, fmt.Errorf("negative value")
+	}
+	v, exact = fromDecimal64(c, int(e)-d64Bias)
+	return v, exact, nil
+}
+
+// FromDecimal64DPD returns a value for an IEEE 754-2008 decimal64 number in Densely Packed Decimal encoding.
+// Returns an error for negative numbers, infinities, and not-a-numbers.
+// Returns false, if the number is out of range and can not be represented precisely.
+func FromDecimal64DPD(d uint64) (v Value, exact bool, err error) {
+	if err := checkDecimal64(d); err != nil {
+		return zero, false, err
+	}
+	comb := d >> (dpdExpContLen + dpdCoeffLen) & 0x1f
+	var e, c uint64
+	if comb>>3 == 3 {
+		e, c = comb>>1&3, 8|comb&1
+	} else {
+		e, c = comb>>3, comb&7
+	}
+	e = e<<dpdExpContLen | d>>dpdCoeffLen&(1<<dpdExpContLen-1)
+	for i := dpdDecletCount - 1; i >= 0; i-- {
+		c = c*1000 + uint64(dpdDecodeTable[d>>(i*dpdDecletLen)&(1<<dpdDecletLen-1)])
+	}
+	if d&d64SignMask != 0 && c != 0 {
+		return zero, false, fmt.Errorf("negative value")
+	}
+	v, exact = fromDecimal64(c, int(e)-d64Bias)
+	return v, exact, nil
+}
+
+func checkDecimal64(d uint64) error {
+	switch d & d64SpecialMask {
+	case d64Inf:
+		return fmt.Errorf("infinity")
+	case d64NaN:
+		return fmt.Errorf("not a number")
+	}
+	return nil
+}
+
+// toDecimal64 returns decimal64 coefficient and biased exponent for v.
+func toDecimal64(v Value) (c, e uint64, exact bool) {
+	m, exp := split(v.Normalized())
+	if m == 0 {
+		return 0, d64Bias, true
+	}
+	exact = true
+	for m > d64MaxCoeff {
+		if m%10 != 0 {
+			exact = false
+		}
+		m /= 10
+		exp++
+	}
+	return m, uint64(int(exp) + d64Bias), exact
+}
+
+// fromDecimal64 returns a value for decimal64 coefficient and unbiased exponent.
+func fromDecimal64(c uint64, e int) (Value, bool) {
+	if c == 0 {
+		return zero, true
+	}
+	exact := true
+	for ; e < minExponent && c > 0; e++ {
+		if c%10 != 0 {
+			exact = false
+		}
+		c /= 10
+	}
+	if c == 0 {
+		return zero, false
+	}
+	for ; e > maxExponent; e-- {
+		if c > maxMantissa/10 {
+			return Max, false
+		}
+		c *= 10
+	}
+	return fromMantAndExp(c, expType(e)), exact
+}
+
+// decodeDeclet decodes a 10-bit declet 'pqr stu v wxy' into a number in [0, 999].
+func decodeDeclet(d uint16) uint16 {
+	bit := func(n uint) uint16 { return d >> n & 1 }
+	pqr, stu, wxy := d>>7&7, d>>4&7, d&7
+	p, q, r := bit(9), bit(8), bit(7)
+	s, t, u := bit(6), bit(5), bit(4)
+	w, x, y := bit(2), bit(1), bit(0)
+	var d2, d1, d0 uint16
+	switch {
+	case bit(3) == 0: // v = 0
+		d2, d1, d0 = pqr, stu, wxy
+	case w == 0 && x == 0: // 100
+		d2, d1, d0 = pqr, stu, 8|y
+	case w == 0 && x == 1: // 101
+		d2, d1, d0 = pqr, 8|u, s<<2|t<<1|y
+	case w == 1 && x == 0: // 110
+		d2, d1, d0 = 8|r, stu, p<<2|q<<1|y
+	case s == 0 && t == 0: // 11100
+		d2, d1, d0 = 8|r, 8|u, p<<2|q<<1|y
+	case s == 0 && t == 1: // 11101
+		d2, d1, d0 = 8|r, p<<2|q<<1|u, 8|y
+	case s == 1 && t == 0: // 11110
+		d2, d1, d0 = pqr, 8|u, 8|y
+	default: // 11111
+		d2, d1, d0 = 8|r, 8|u, 8|y
+	}
+	return d2*100 + d1*10 + d0
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal64(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v        Value
+		bid, dpd uint64
+		exact    bool
+	}{
+		{zero, 0x31c0000000000000, 0x2238000000000000, true},
+		{MustFromString("1"), 0x31c0000000000001, 0x2238000000000001, true},
+		{MustFromString("0.1"), 0x31a0000000000001, 0x2234000000000001, true},
+		{MustFromString("10"), 0x31e0000000000001, 0x223c000000000001, true},
+		{MustFromString("9999999999999999"), 0x6c7386f26fc0ffff, 0x6e38ff3fcff3fcff, true},
+		{MustFromString("1234567890123456e1"), 0x31e462d53c8abac0, 0x263d34b9c1e28e56, true},
+		{FromMantAndExp(12345678901234567, 0), 0x31e462d53c8abac0, 0x263d34b9c1e28e56, false},
+		{FromMantAndExp(123, maxExponent), 0x31c000000000007b + uint64(maxExponent)<<53, 0, true},
+		{FromMantAndExp(1, minExponent), 0x31c0000000000001 - uint64(-minExponent)<<53, 0, true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			bid, exact := test.v.ToDecimal64BID()
+			a.Equal(test.exact, exact)
+			a.Equalf(test.bid, bid, "%x != %x", test.bid, bid)
+			v, exact, err := FromDecimal64BID(bid)
+			if a.NoError(err) {
+				a.True(exact)
+				a.Equal(test.v.Eq(v), test.exact, "%#v != %#v", test.v, v)
+			}
+
+			dpd, exact := test.v.ToDecimal64DPD()
+			a.Equal(test.exact, exact)
+			if test.dpd != 0 {
+				a.Equalf(test.dpd, dpd, "%x != %x", test.dpd, dpd)
+			}
+			v, exact, err = FromDecimal64DPD(dpd)
+			if a.NoError(err) {
+				a.True(exact)
+				a.Equal(test.v.Eq(v), test.exact, "%#v != %#v", test.v, v)
+			}
+		})
+	}
+}
+
+func TestFromDecimal64(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		bid, dpd uint64
+		v        Value
+		exact    bool
+		err      string
+	}{
+		{0x31c0000000000001, 0x2238000000000001, MustFromString("1"), true, ""},
+		{0xb1c0000000000000, 0xa238000000000000, zero, true, ""},  // -0
+		{0x0000000000000001, 0x0000000000000001, zero, false, ""}, // 1e-398
+		{0x77fb86f26fc0ffff, 0x77fcff3fcff3fcff, Max, false, ""},  // 9.999999999999999e384
+		{0x6c7386f26fc0ffff + 1, 0, zero, true, ""},               // non-canonical BID
+		{0, 0x22380000000003ff, MustFromString("999"), true, ""},  // non-canonical DPD declet
+		{0xb1c0000000000001, 0xa238000000000001, zero, false, "negative value"},
+		{0x7800000000000000, 0x7800000000000000, zero, false, "infinity"},
+		{0x7c00000000000000, 0x7c00000000000000, zero, false, "not a number"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			check := func(v Value, exact bool, err error) {
+				if len(test.err) > 0 {
+					a.EqualError(err, test.err)
+					return
+				}
+				if a.NoError(err) {
+					a.Equal(test.exact, exact)
+					a.True(test.v.Eq(v), "%#v != %#v", test.v, v)
+				}
+			}
+			if test.bid != 0 {
+				check(FromDecimal64BID(test.bid))
+			}
+			if test.dpd != 0 {
+				check(FromDecimal64DPD(test.dpd))
+			}
+		})
+	}
+}
+
+func TestDeclets(t *testing.T) {
+	a := assert.New(t)
+	for i := 0; i < 1000; i++ {
+		a.Equal(uint16(i), dpdDecodeTable[dpdEncodeTable[i]])
+	}
+	a.Equal(uint16(0x0ff), dpdEncodeTable[999])
+	a.Equal(uint16(0x0a3), dpdEncodeTable[123])
+	a.Equal(uint16(0x008), dpdEncodeTable[8])
+}
+
+func TestDecimal64Random(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v := FromMantAndExp(uint64(rnd.Int63n(d64MaxCoeff+1)), int32(rnd.Intn(maxExponent-minExponent)+minExponent))
+		bid, exact := v.ToDecimal64BID()
+		a.True(exact)
+		fromBID, exact, err := FromDecimal64BID(bid)
+		a.NoError(err)
+		a.True(exact)
+		dpd, exact := v.ToDecimal64DPD()
+		a.True(exact)
+		fromDPD, exact, err := FromDecimal64DPD(dpd)
+		a.NoError(err)
+		a.True(exact)
+		if !a.True(v.Eq(fromBID) && v.Eq(fromDPD), "%#v: %#v %#v", v, fromBID, fromDPD) {
+			break
+		}
+	}
+}
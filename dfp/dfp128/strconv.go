@@ -0,0 +1,193 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp128
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	delim = '.'
+)
+
+type posError struct {
+	pos int
+	err string
+}
+
+func newPosError(err string, pos int) *posError {
+	return &posError{err: err, pos: pos}
+}
+
+func (pe posError) Error() string {
+	return pe.err + fmt.Sprintf(" at pos %d", pe.pos)
+}
+
+func addPosErrorOffset(err error, offset int) error {
+	var pe *posError
+	if !errors.As(err, &pe) { // try to locate error position.
+		return err
+	}
+	pe.pos += offset
+	return pe
+}
+
+func parse(s string) (digits string, e int, neg bool, err error) {
+	s, offset, neg := prepareString(s)
+	if len(s) == 0 {
+		return "", 0, false, fmt.Errorf("empty input")
+	}
+	digits, e, err = doParse(s)
+	if err != nil {
+		// add what we've trimmed before and add +1 to the offset to start indices from 1.
+		err = fmt.Errorf("parsing failed: %w", addPosErrorOffset(err, offset+1))
+	}
+	return digits, e, neg, err
+}
+
+// doParse parses given decimal string.
+// returns a string without leading and trailing zeros, and an exponent
+func doParse(s string) (result string, e int, err error) {
+	result, delimPos, e, err := removeLeadingZeros(s)
+	if err != nil {
+		return "", 0, err
+	}
+	result, eFromDelim := removeTrailingZerosString(result, delimPos)
+	return result, e + eFromDelim, nil
+}
+
+// prepareString cleans the string from ",-,+ symbols, and spaces.
+func prepareString(s string) (prepared string, offset int, neg bool) {
+	if len(s) > 0 && s[0] == '"' {
+		s = s[1:]
+		offset++
+	}
+	if len(s) > 0 && s[len(s)-1] == '"' {
+		s = s[:len(s)-1]
+	}
+	if trimmed := strings.TrimLeftFunc(s, unicode.IsSpace); len(trimmed) != len(s) {
+		offset += len(s) - len(trimmed)
+		s = trimmed
+	}
+	s = strings.TrimRightFunc(s, unicode.IsSpace)
+	if len(s) == 0 {
+		return "", 0, false
+	}
+	if s[0] == '-' {
+		neg = true
+		offset++
+		s = s[1:]
+	} else if s[0] == '+' {
+		offset++
+		s = s[1:]
+	}
+	return s, offset, neg
+}
+
+func removeLeadingZeros(s string) (result string, delimPos int, e int, err error) {
+	var b strings.Builder
+	delimPos, firstNonZeroPos := -1, -1
+outer:
+	for i, r := range s {
+		switch {
+		case '0' <= r && r <= '9':
+			if b.Len() == 0 {
+				if r == '0' { // trim leading zeros
+					continue
+				}
+				firstNonZeroPos = i
+			}
+			b.WriteRune(r)
+		case r == 'e':
+			parsed, err := strconv.ParseInt(s[i+1:], 10, 32)
+			if err != nil {
+				return "", 0, 0, newPosError("error parsing exponent: "+err.Error(), i+1)
+			}
+			e = int(parsed)
+			break outer
+		case r == delim:
+			if delimPos != -1 {
+				return "", 0, 0, newPosError("unexpected delimeter", i)
+			}
+			delimPos = i
+		default:
+			return "", 0, 0, newPosError(fmt.Sprintf("unexpected symbol %q", r), i)
+		}
+	}
+	if firstNonZeroPos == -1 { // a zero-only string
+		return "", 0, 0, nil
+	}
+
+	result = b.String()
+
+	// move delimPos to the beginning of the trimmed string
+	if delimPos >= 0 {
+		if delimPos < firstNonZeroPos {
+			firstNonZeroPos--
+		}
+		delimPos -= firstNonZeroPos
+	} else { // if there is no delim, add one at the end of the string 123 --> 123.
+		delimPos = len(result)
+	}
+
+	return result, delimPos, e, nil
+}
+
+func removeTrailingZerosString(s string, delimPos int) (result string, e int) {
+	s = strings.TrimRight(s, "0")
+	return s, delimPos - len(s)
+}
+
+// parseMant parses a string of decimal digits, that fits uint128.
+func parseMant(digits string) uint128 {
+	var m uint128
+	for _, r := range digits {
+		m, _ = m.mul64(10)
+		m = m.add(uint128{lo: uint64(r - '0')})
+	}
+	return m
+}
+
+func formatMantExp(mant uint128, exp int, format rune, w io.Writer) {
+	switch format {
+	case 'f', 's':
+		formatAsDecimal(mant, exp, w)
+	default:
+		formatWithExponent(mant, exp, w)
+	}
+}
+
+func formatAsDecimal(mant uint128, exp int, w io.Writer) {
+	if mant.isZero() {
+		io.WriteString(w, "0")
+		return
+	}
+	mString := mant.String()
+	switch {
+	case exp >= 0:
+		io.WriteString(w, mString)
+		io.WriteString(w, strings.Repeat("0", exp))
+	default:
+		if diff := len(mString) + exp; diff <= 0 { // add leading zeros and a delimiter
+			io.WriteString(w, "0.")
+			io.WriteString(w, strings.Repeat("0", -diff))
+			io.WriteString(w, mString)
+		} else { // insert a delimeter
+			io.WriteString(w, mString[:diff])
+			io.WriteString(w, ".")
+			io.WriteString(w, mString[diff:])
+		}
+	}
+}
+
+func formatWithExponent(mant uint128, exp int, w io.Writer) {
+	io.WriteString(w, mant.String())
+	if !mant.isZero() {
+		io.WriteString(w, "e"+strconv.Itoa(exp))
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp128
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// uint128 is an unsigned 128-bit integer.
+type uint128 struct {
+	hi, lo uint64
+}
+
+// uint256 is an unsigned 256-bit integer. The least significant word goes first.
+type uint256 [4]uint64
+
+var (
+	one = uint128{lo: 1}
+
+	// pow10Table contains powers of 10 up to 1e38.
+	pow10Table = makePow10Table()
+)
+
+func makePow10Table() (table [39]uint128) {
+	table[0] = one
+	for i := 1; i < len(table); i++ {
+		table[i], _ = table[i-1].mul64(10)
+	}
+	return table
+}
+
+func (u uint128) isZero() bool {
+	return u.hi == 0 && u.lo == 0
+}
+
+func (u uint128) cmp(other uint128) int {
+	switch {
+	case u.hi > other.hi:
+		return 1
+	case u.hi < other.hi:
+		return -1
+	case u.lo > other.lo:
+		return 1
+	case u.lo < other.lo:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// add returns u+other. It does not check for overflow.
+func (u uint128) add(other uint128) uint128 {
+	lo, carry := bits.Add64(u.lo, other.lo, 0)
+	hi, _ := bits.Add64(u.hi, other.hi, carry)
+	return uint128{hi: hi, lo: lo}
+}
+
+// sub returns u-other. It does not check for underflow.
+func (u uint128) sub(other uint128) uint128 {
+	lo, borrow := bits.Sub64(u.lo, other.lo, 0)
+	hi, _ := bits.Sub64(u.hi, other.hi, borrow)
+	return uint128{hi: hi, lo: lo}
+}
+
+// mul64 returns u*m and a flag indicating that the result overflows 128 bits.
+func (u uint128) mul64(m uint64) (uint128, bool) {
+	hi, lo := bits.Mul64(u.lo, m)
+	carry, mid := bits.Mul64(u.hi, m)
+	hi, c := bits.Add64(hi, mid, 0)
+	return uint128{hi: hi, lo: lo}, carry != 0 || c != 0
+}
+
+// mul returns u*other and a flag indicating that the result overflows 128 bits.
+func (u uint128) mul(other uint128) (uint128, bool) {
+	p := mul256(u, other)
+	return uint128{hi: p[1], lo: p[0]}, p[2] != 0 || p[3] != 0
+}
+
+// divMod64 returns u/d and u%d.
+func (u uint128) divMod64(d uint64) (quo uint128, rem uint64) {
+	quo.hi, rem = bits.Div64(0, u.hi, d)
+	quo.lo, rem = bits.Div64(rem, u.lo, d)
+	return quo, rem
+}
+
+// divMod returns u/d and u%d.
+func (u uint128) divMod(d uint128) (quo, rem uint128) {
+	return uint256{u.lo, u.hi}.divMod(d)
+}
+
+func (u uint128) bitLen() int {
+	if u.hi != 0 {
+		return 64 + bits.Len64(u.hi)
+	}
+	return bits.Len64(u.lo)
+}
+
+// digits returns the number of decimal digits in u.
+// see https://graphics.stanford.edu/~seander/bithacks.html#IntegerLog10
+func (u uint128) digits() int {
+	if u.isZero() {
+		return 1
+	}
+	d := u.bitLen() * 1233 >> 12
+	if u.cmp(pow10Table[d]) >= 0 {
+		d++
+	}
+	return d
+}
+
+func (u uint128) String() string {
+	if u.hi == 0 {
+		return strconv.FormatUint(u.lo, 10)
+	}
+	const chunk = 1e19
+	quo, rem := u.divMod64(chunk)
+	s := strconv.FormatUint(rem, 10)
+	return quo.String() + strings.Repeat("0", 19-len(s)) + s
+}
+
+// mul256 returns a full 256-bit product of a and b.
+func mul256(a, b uint128) (r uint256) {
+	var c uint64
+	hi, lo := bits.Mul64(a.lo, b.lo)
+	r[0], r[1] = lo, hi
+	hi, lo = bits.Mul64(a.lo, b.hi)
+	r[1], c = bits.Add64(r[1], lo, 0)
+	r[2], _ = bits.Add64(hi, 0, c)
+	hi, lo = bits.Mul64(a.hi, b.lo)
+	r[1], c = bits.Add64(r[1], lo, 0)
+	r[2], c = bits.Add64(r[2], hi, c)
+	r[3] = c
+	hi, lo = bits.Mul64(a.hi, b.hi)
+	r[2], c = bits.Add64(r[2], lo, 0)
+	r[3], _ = bits.Add64(r[3], hi, c)
+	return r
+}
+
+// mul64 returns u*m. It does not check for overflow.
+func (u uint256) mul64(m uint64) (r uint256) {
+	var carry uint64
+	for i := range u {
+		hi, lo := bits.Mul64(u[i], m)
+		var c uint64
+		r[i], c = bits.Add64(lo, carry, 0)
+		carry = hi + c
+	}
+	return r
+}
+
+// divMod64 returns u/d and u%d.
+func (u uint256) divMod64(d uint64) (quo uint256, rem uint64) {
+	for i := len(u) - 1; i >= 0; i-- {
+		quo[i], rem = bits.Div64(rem, u[i], d)
+	}
+	return quo, rem
+}
+
+// divMod returns u/d and u%d. The quotient must fit 128 bits.
+func (u uint256) divMod(d uint128) (quo, rem uint128) {
+	if d.hi == 0 {
+		q, r := u.divMod64(d.lo)
+		return uint128{hi: q[1], lo: q[0]}, uint128{lo: r}
+	}
+	// a simple binary long division.
+	for i := u.bitLen() - 1; i >= 0; i-- {
+		carry := rem.hi >> 63
+		rem = uint128{hi: rem.hi<<1 | rem.lo>>63, lo: rem.lo<<1 | u[i/64]>>(uint(i)%64)&1}
+		quo = uint128{hi: quo.hi<<1 | quo.lo>>63, lo: quo.lo << 1}
+		// if the highest bit was shifted out, rem is definitely greater than d.
+		if carry != 0 || rem.cmp(d) >= 0 {
+			rem = rem.sub(d)
+			quo.lo |= 1
+		}
+	}
+	return quo, rem
+}
+
+func (u uint256) bitLen() int {
+	for i := len(u) - 1; i >= 0; i-- {
+		if u[i] != 0 {
+			return i*64 + bits.Len64(u[i])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp128
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUint128Digits(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(1, uint128{}.digits())
+	a.Equal(1, one.digits())
+	for i := 1; i < len(pow10Table); i++ {
+		a.Equal(i+1, pow10Table[i].digits())
+		a.Equal(i, pow10Table[i].sub(one).digits())
+	}
+	a.Equal(39, uint128{hi: ^uint64(0), lo: ^uint64(0)}.digits())
+	a.Equal("340282366920938463463374607431768211455", uint128{hi: ^uint64(0), lo: ^uint64(0)}.String())
+}
+
+func TestUint128Random(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	toBig := func(u uint128) *big.Int {
+		b := new(big.Int).SetUint64(u.hi)
+		return b.Lsh(b, 64).Or(b, new(big.Int).SetUint64(u.lo))
+	}
+	random := func() uint128 {
+		u := uint128{hi: rnd.Uint64(), lo: rnd.Uint64()}
+		if shift := uint(rnd.Intn(128)); shift >= 64 {
+			return uint128{lo: u.lo >> (shift - 64)}
+		} else {
+			return uint128{hi: u.hi >> shift, lo: u.lo}
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		x, y := random(), random()
+		bx, by := toBig(x), toBig(y)
+		a.Equal(bx.String(), x.String())
+		a.Equal(len(bx.String()), x.digits())
+		a.Equal(bx.Cmp(by), x.cmp(y))
+
+		p := mul256(x, y)
+		bp := new(big.Int).Mul(bx, by)
+		for j := 3; j >= 0; j-- {
+			a.Equal(new(big.Int).Rsh(bp, uint(j*64)).Uint64(), p[j])
+		}
+		if y.isZero() {
+			continue
+		}
+		q, r := x.divMod(y)
+		bq, br := new(big.Int).QuoRem(bx, by, new(big.Int))
+		a.Equal(bq.String(), q.String())
+		if !a.Equal(br.String(), r.String()) {
+			break
+		}
+		// divide a 256-bit product by a number, so that the quotient fits 128 bits.
+		w := random()
+		if w.cmp(y) < 0 {
+			w = y
+		}
+		q, r = p.divMod(w)
+		bq, br = new(big.Int).QuoRem(bp, toBig(w), new(big.Int))
+		a.Equal(bq.String(), q.String())
+		a.Equal(br.String(), r.String())
+	}
+}
@@ -0,0 +1,673 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package dfp128 implements a 128-bit decimal floating-point number.
+// It has the same API as dfp.Value, but provides up to 34 digits of precision,
+// so it can be used for large sums or crypto currency amounts with 18 decimal places.
+package dfp128
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	expBits    = 15
+	mantBits   = 128 - expBits
+	hiMantBits = mantBits - 64
+	bias       = 1<<(expBits-1) - 1
+	// maxExponent is 16384.
+	maxExponent = 1 << (expBits - 1)
+	// minExponent is -16383.
+	minExponent = -bias
+
+	expMask    = 1<<expBits - 1
+	hiMantMask = 1<<hiMantBits - 1
+)
+
+var (
+	// maxMantissa is 10384593717069655257060992658440191 (2^113-1).
+	maxMantissa = uint128{hi: hiMantMask, lo: math.MaxUint64}
+	minMantissa = one
+
+	digitsInMaxMantissa = maxMantissa.digits()
+
+	jsonParts = []string{`{"m":`, `,"e":`, `}`}
+	jsonLen   = len(jsonParts[0]) + len(jsonParts[1]) + len(jsonParts[2])
+
+	zero = Value{}
+)
+
+var (
+	// Max is the maximum possible value.
+	Max = fromMantAndExp(maxMantissa, maxExponent)
+	// Min is the minimum possible value.
+	Min = fromMantAndExp(minMantissa, minExponent)
+)
+
+// Value is a positive decimal floating-point number.
+// It uses two uint64 values as a data type, where
+// 15 bits are used for exponent and 113 for mantissa.
+//
+//	127            112                                                                       0
+//	_______________|__________________________________________________________________________
+//	eeeeeeeeeeeeeeemmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmmm...
+//
+// Values are comparable, however, like dfp.Value, the same number can have different representations.
+type Value struct {
+	hi, lo uint64
+}
+
+// FromUint64 returns a value for given uint64 number.
+func FromUint64(v uint64) Value {
+	return adjustMantExp(uint128{lo: v}, 0)
+}
+
+// FromMantAndExp returns a value for given mantissa and exponent.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromMantAndExp(mant uint64, exp int32) Value {
+	return FromMant128AndExp(0, mant, exp)
+}
+
+// FromMant128AndExp returns a value for given 128-bit mantissa and exponent.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromMant128AndExp(hi, lo uint64, exp int32) Value {
+	return adjustMantExp(uint128{hi: hi, lo: lo}, int(exp)).Normalized()
+}
+
+// FromValue returns a value for given dfp.Value. The conversion is always lossless.
+func FromValue(v dfp.Value) Value {
+	return FromMantAndExp(v.MantUint64(), v.Exp())
+}
+
+// FromFloat64 returns a value for given float64 value.
+// The result is the shortest decimal, that represents the same float64 number.
+// Returns an error for nagative values, infinities, and not-a-numbers.
+func FromFloat64(v float64) (Value, error) {
+	if v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return zero, fmt.Errorf("bad float number")
+	}
+	return FromString(strconv.FormatFloat(v, 'e', -1, 64))
+}
+
+// MustFromFloat64 returns a value for given float64 value. It panics on an error.
+func MustFromFloat64(f float64) Value {
+	v, err := FromFloat64(f)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// FromString parses a string into a value.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func FromString(s string) (Value, error) {
+	parsed, e, neg, err := parse(s)
+	if neg {
+		return zero, fmt.Errorf("negative value")
+	}
+	if err != nil { // could still be a float
+		if f, fltErr := strconv.ParseFloat(s, 64); fltErr == nil {
+			return FromFloat64(f)
+		}
+		return zero, err
+	}
+	return fromStringAndExp(parsed, e), nil
+}
+
+// MustFromString parses a string into a value. It panics on an error.
+func MustFromString(s string) Value {
+	v, err := FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// fromStringAndExp parses a string without leading and trailing zeros into Value.
+func fromStringAndExp(digits string, e int) Value {
+	if len(digits) == 0 {
+		return zero
+	}
+	if toCut := len(digits) - digitsInMaxMantissa; toCut > 0 {
+		e += toCut
+		digits = digits[:digitsInMaxMantissa]
+	}
+	return adjustMantExp(parseMant(digits), e)
+}
+
+// MarshalJSON marshals value according to current dfp.JSONMode.
+// See dfp.JSONMode and dfp.JSONMode* constants.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return v.toJSON(dfp.JSONMode), nil
+}
+
+func (v Value) toJSON(mode int) []byte {
+	switch mode {
+	case dfp.FormatFloat:
+		return []byte(strconv.FormatFloat(v.Float64(), 'f', -1, 64))
+	case dfp.FormatJSONObject:
+		return []byte(toMEJSON(v))
+	case dfp.JSONModeCompact:
+		if decimalFormatLen(v)+2 <= jsonMEFormatLen(v) { // +2 for a pair of quotes
+			return v.toJSON(dfp.FormatString)
+		}
+		return v.toJSON(dfp.FormatJSONObject)
+	default: // marshal as a string
+		var builder strings.Builder
+		builder.WriteRune('"')
+		m, e := split(v)
+		formatMantExp(m, e, 'f', &builder)
+		builder.WriteRune('"')
+		return []byte(builder.String())
+	}
+}
+
+func toMEJSON(v Value) string {
+	var builder strings.Builder
+	m, e := split(v)
+	builder.WriteString(jsonParts[0])
+	builder.WriteString(m.String())
+	builder.WriteString(jsonParts[1])
+	builder.WriteString(strconv.Itoa(e))
+	builder.WriteString(jsonParts[2])
+	return builder.String()
+}
+
+// UnmarshalJSON unmarshals a string, float, or an object into a value.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty json")
+	}
+	switch data[0] {
+	case '{':
+		d := struct {
+			M json.Number
+			E int32
+		}{}
+		if err := json.Unmarshal(data, &d); err != nil {
+			return err
+		}
+		m, err := FromString(d.M.String())
+		if err != nil {
+			return err
+		}
+		*v = adjustMantExp(mant(m), exp(m)+int(d.E))
+	default:
+		value, err := FromString(string(data))
+		if err != nil {
+			return err
+		}
+		*v = value
+	}
+	return nil
+}
+
+// GoString returns debug string representation.
+func (v Value) GoString() string {
+	m, e := split(v)
+	return fmt.Sprintf("{%v, %v}", m, e)
+}
+
+// String returns a string representation of the value.
+func (v Value) String() string {
+	var builder strings.Builder
+	m, e := split(v.Normalized())
+	formatMantExp(m, e, 'f', &builder)
+	return builder.String()
+}
+
+// Format implements fmt.Formatter and allows to format values as a string.
+//
+//	'f', 's' will produce a decimal string, e.g. 123.456
+//	'e', 'v' will produce scientific notation, e.g. 123456e7
+func (v Value) Format(f fmt.State, c rune) {
+	m, e := split(v.Normalized())
+	formatMantExp(m, e, c, f)
+}
+
+// Mant128 returns v's mantissa as is.
+func (v Value) Mant128() (hi, lo uint64) {
+	m := mant(v)
+	return m.hi, m.lo
+}
+
+// Exp returns v's exponent as is.
+func (v Value) Exp() int32 {
+	return int32(exp(v))
+}
+
+// Eq returns true if both values represent the same number.
+func (v Value) Eq(other Value) bool {
+	if v == other {
+		return true
+	}
+	return v.Normalized() == other.Normalized()
+}
+
+// IsZero returns true if the value has zero mantissa.
+func (v Value) IsZero() bool {
+	return mant(v).isZero()
+}
+
+// ToValue converts v into a dfp.Value. Returns false, if v can not be represented precisely.
+// In this case the least significant digits are truncated, or the result becomes zero or dfp.Max.
+func (v Value) ToValue() (dfp.Value, bool) {
+	m, e := split(v.Normalized())
+	for m.hi != 0 {
+		m, _ = m.divMod64(10)
+		e++
+	}
+	result := dfp.FromMantAndExp(m.lo, int32(e))
+	return result, FromValue(result).Eq(v)
+}
+
+// Uint64 returns the integer part of the value as a uint64 number.
+// If it does not fit uint64, math.MaxUint64 is returned.
+func (v Value) Uint64() uint64 {
+	m, e := split(v.Normalized())
+	if e < 0 {
+		if -e >= len(pow10Table) {
+			return 0
+		}
+		m, _ = m.divMod(pow10Table[-e])
+	} else if e > 0 {
+		var overflow bool
+		if e >= len(pow10Table) {
+			overflow = !m.isZero()
+		} else {
+			m, overflow = m.mul(pow10Table[e])
+		}
+		if overflow {
+			return math.MaxUint64
+		}
+	}
+	if m.hi != 0 {
+		return math.MaxUint64
+	}
+	return m.lo
+}
+
+// Float64 returns the nearest float64 value.
+func (v Value) Float64() float64 {
+	m, e := split(v)
+	f, _ := strconv.ParseFloat(m.String()+"e"+strconv.Itoa(e), 64)
+	return f
+}
+
+// Normalized eliminates trailing zeros in the mantissa.
+// The process increases the exponent, and stops if it exceeds the maximum possible exponent,
+// so that it is possible, that the mantissa will still have trailing zeros.
+func (v Value) Normalized() Value {
+	m, e := split(v)
+	if m.isZero() {
+		return zero
+	}
+	return fromMantAndExp(trimZeros(m, e, maxExponent))
+}
+
+// Cmp compares two values.
+// Returns -1 if a < b, 0 if a == b, 1 if a > b.
+func (v Value) Cmp(other Value) int {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	ediff := e1 - e2
+
+	if ediff == 0 || m1.isZero() || m2.isZero() {
+		return m1.cmp(m2)
+	}
+
+	// compare highest decimal digit position
+	maxDigit1 := e1 + m1.digits()
+	maxDigit2 := e2 + m2.digits()
+	if maxDigit1 > maxDigit2 {
+		return 1
+	} else if maxDigit1 < maxDigit2 {
+		return -1
+	}
+
+	// the mantissa with more digits has at most digitsInMaxMantissa digits, so the product fits 128 bits.
+	if ediff > 0 {
+		m1, _ = m1.mul(pow10Table[ediff])
+	} else {
+		m2, _ = m2.mul(pow10Table[-ediff])
+	}
+	return m1.cmp(m2)
+}
+
+// Floor returns the nearest value less than or equal to v that has prec decimal places.
+// Note that prec can be negative.
+func (v Value) Floor(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundDown)
+}
+
+// Round rounds the value to prec decimal places.
+// Note that prec can be negative.
+func (v Value) Round(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundNearest)
+}
+
+// Ceil returns the nearest value greater than or equal to v that has prec decimal places.
+// Note that prec can be negative.
+func (v Value) Ceil(prec int) Value {
+	return v.RoundTo(prec, dfp.RoundUp)
+}
+
+// RoundTo rounds the value to prec decimal places using given rounding mode.
+// Note that prec can be negative.
+func (v Value) RoundTo(prec int, mode dfp.RoundingMode) Value {
+	m, e := split(v)
+	return adjustMantExp(round(m, e, prec, mode))
+}
+
+// Add returns the sum of two values.
+// If the resulting mantissa overflows max mantissa, the least significant digits will be truncated.
+// If the result overflows Max, Max is returned.
+func (v Value) Add(other Value) Value {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	// first, check for obvious cases, when one of the arguments is zero
+	if m1.isZero() {
+		if m2.isZero() {
+			return zero
+		}
+		return other
+	}
+	if m2.isZero() {
+		return v
+	}
+	m1, m2, e := toEqualExp(m1, e1, m2, e2)
+	res := m1.add(m2)
+	if res.cmp(maxMantissa) > 0 {
+		if e == maxExponent {
+			return Max
+		}
+		res, _ = res.divMod64(10)
+		e++
+	}
+	return fromMantAndExp(res, e)
+}
+
+// Sub returns |a-b| and a boolean flag indicating that the result is negative.
+func (v Value) Sub(other Value) (Value, bool) {
+	m1, e1 := split(v)
+	m2, e2 := split(other)
+	// first, check for obvious cases, when one of the arguments is zero
+	if m2.isZero() {
+		if m1.isZero() {
+			return zero, false
+		}
+		return v, false
+	}
+	if m1.isZero() {
+		return other, true
+	}
+	m1, m2, e := toEqualExp(m1, e1, m2, e2)
+	if m1.cmp(m2) >= 0 {
+		return fromMantAndExp(m1.sub(m2), e), false
+	}
+	return fromMantAndExp(m2.sub(m1), e), true
+}
+
+// Mul returns v * other.
+// If the result underflows Min, zero is returned.
+// If the result overflows Max, Max is returned.
+// If the resulting mantissa overflows max mantissa, the least significant digits will be truncated.
+func (v Value) Mul(other Value) Value {
+	m1, e1 := split(v.Normalized())
+	m2, e2 := split(other.Normalized())
+	// first, check for obvious cases, when one of the arguments is zero
+	if m1.isZero() || m2.isZero() {
+		return zero
+	}
+	// a*10^e1 * b*10^e2 = a * b * 10^(e1+e2)
+	// perform a 256-bit multiplication, then cut the digits, that don't fit the mantissa.
+	p, e := mul256(m1, m2), e1+e2
+	for cut := p.bitLen()*1233>>12 - digitsInMaxMantissa; cut > 0; {
+		n := cut
+		if n > 19 {
+			n = 19
+		}
+		p, _ = p.divMod64(pow10Table[n].lo)
+		e += n
+		cut -= n
+	}
+	return adjustMantExp(uint128{hi: p[1], lo: p[0]}, e)
+}
+
+// Div calculates a/b. If b == 0, Div panics.
+// The result has up to 34 significant digits, the rest of the digits are truncated.
+func (v Value) Div(other Value) Value {
+	return v.Quo(other, -minExponent, dfp.RoundDown).Normalized()
+}
+
+// Quo calculates a/b rounded to prec decimal places using given rounding mode. If b == 0, Quo panics.
+// If the quotient has more than 34 digits, the least significant digits are rounded.
+// Note that prec can be negative.
+func (v Value) Quo(other Value, prec int, mode dfp.RoundingMode) Value {
+	m1, e1 := split(v.Normalized())
+	m2, e2 := split(other.Normalized())
+	if m2.isZero() {
+		panic("division by zero")
+	}
+	if m1.isZero() {
+		return zero
+	}
+	return adjustMantExp(quo(m1, e1, m2, e2, prec, mode))
+}
+
+// quo calculates m1*10^e1 / m2*10^e2 so, that the result has at most prec decimal places,
+// and its mantissa has no more than digitsInMaxMantissa-1 digits.
+func quo(m1 uint128, e1 int, m2 uint128, e2 int, prec int, mode dfp.RoundingMode) (uint128, int) {
+	// m1*10^e1 / m2*10^e2 = (m1*10^shift / m2) * 10^-prec, where shift = e1-e2+prec.
+	shift := e1 - e2 + prec
+	if qDigits := quoDigits(m1, m2) + shift; qDigits > digitsInMaxMantissa-1 {
+		cut := qDigits - (digitsInMaxMantissa - 1)
+		shift -= cut
+		prec -= cut
+	}
+	if -prec < minExponent {
+		shift -= minExponent + prec
+		prec = -minExponent
+	}
+	var q, r, d uint128
+	if shift >= 0 {
+		// the quotient has less than digitsInMaxMantissa digits, so the dividend fits 256 bits.
+		n := uint256{m1.lo, m1.hi}
+		for ; shift > 0; shift -= 19 {
+			n = n.mul64(pow10Table[min(shift, 19)].lo)
+		}
+		d = m2
+		q, r = n.divMod(d)
+	} else {
+		var overflow bool
+		if -shift < len(pow10Table) {
+			d, overflow = m2.mul(pow10Table[-shift])
+		}
+		if d.isZero() || overflow { // the divisor doesn't fit 128 bits, so the quotient is zero.
+			if mode == dfp.RoundUp {
+				return one, -prec
+			}
+			return uint128{}, 0
+		}
+		q, r = m1.divMod(d)
+	}
+	if roundUp(r, d, mode) {
+		q = q.add(one)
+	}
+	return q, -prec
+}
+
+// quoDigits returns the number of decimal digits in the integer part of m1/m2,
+// if m1 and m2 had the same number of digits.
+func quoDigits(m1, m2 uint128) int {
+	d1, d2 := m1.digits(), m2.digits()
+	if d1 < d2 {
+		m1, _ = m1.mul(pow10Table[d2-d1])
+	} else {
+		m2, _ = m2.mul(pow10Table[d1-d2])
+	}
+	if m1.cmp(m2) < 0 {
+		return d1 - d2
+	}
+	return d1 - d2 + 1
+}
+
+// round cuts the digits of m*10^e, so that it has at most prec decimal places.
+func round(m uint128, e, prec int, mode dfp.RoundingMode) (uint128, int) {
+	toCut := -prec - e
+	if toCut <= 0 || m.isZero() {
+		return m, e
+	}
+	if toCut > m.digits() { // all the digits are cut, so the value is less than a half.
+		if mode == dfp.RoundUp {
+			return one, -prec
+		}
+		return uint128{}, 0
+	}
+	d := pow10Table[toCut]
+	q, r := m.divMod(d)
+	if roundUp(r, d, mode) {
+		q = q.add(one)
+	}
+	return q, -prec
+}
+
+// roundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func roundUp(r, d uint128, mode dfp.RoundingMode) bool {
+	switch mode {
+	case dfp.RoundNearest:
+		return r.cmp(d.sub(r)) > 0
+	case dfp.RoundHalfUp:
+		return !r.isZero() && r.cmp(d.sub(r)) >= 0
+	case dfp.RoundUp:
+		return !r.isZero()
+	default:
+		return false
+	}
+}
+
+// toEqualExp changes m1 and m2 in such a way, that e1 == e2.
+// the result can be used to calculate m1+m2, m1-m2.
+// if the difference between the exponents is too big, m2 can lose some (or all) digits.
+func toEqualExp(m1 uint128, e1 int, m2 uint128, e2 int) (r1, r2 uint128, re int) {
+	if e1 >= e2 {
+		return doToEqualExp(m1, e1, m2, e2)
+	}
+	r1, r2, re = doToEqualExp(m2, e2, m1, e1)
+	return r2, r1, re
+}
+
+// doToEqualExp is a helper for toEqualExp. it assumes that e1 >= e2.
+func doToEqualExp(m1 uint128, e1 int, m2 uint128, e2 int) (r1, r2 uint128, re int) {
+	if e1 == e2 {
+		return m1, m2, e1
+	}
+
+	// try to trim trailing zeros for m2.
+	m2, e2 = trimZeros(m2, e2, e1)
+	if e1 == e2 {
+		return m1, m2, e1
+	}
+
+	// next, try to increase m1 and decrease e1 so, that e1 == e2.
+	q, _ := maxMantissa.divMod(m1)
+	toMult := min(q.digits()-1, e1-e2)
+	m1, _ = m1.mul(pow10Table[toMult])
+	e1 -= toMult
+
+	// last resort, decrease m2, lose some digits.
+	if ediff := e1 - e2; ediff >= len(pow10Table) {
+		m2 = uint128{}
+	} else if ediff > 0 {
+		m2, _ = m2.divMod(pow10Table[ediff])
+	}
+	return m1, m2, e1
+}
+
+func trimZeros(m uint128, e, eMax int) (uint128, int) {
+	for e < eMax {
+		q, r := m.divMod64(10)
+		if r != 0 {
+			break
+		}
+		m = q
+		e++
+	}
+	return m, e
+}
+
+func adjustMantExp(m uint128, e int) Value {
+	// fix too large matissa, or too small exponent
+	for (m.cmp(maxMantissa) > 0 || e < minExponent) && !m.isZero() {
+		m, _ = m.divMod64(10)
+		e++
+	}
+
+	// fix too large exponent
+	for e > maxExponent && !m.isZero() {
+		m10, overflow := m.mul64(10)
+		if overflow || m10.cmp(maxMantissa) > 0 {
+			break
+		}
+		m = m10
+		e--
+	}
+
+	if m.isZero() {
+		return zero
+	}
+	if e > maxExponent {
+		return Max
+	}
+	return fromMantAndExp(m, e)
+}
+
+func jsonMEFormatLen(v Value) int {
+	m, e := split(v)
+	return jsonLen + m.digits() + len(strconv.Itoa(e))
+}
+
+func decimalFormatLen(v Value) int {
+	m, e := split(v)
+	if m.isZero() {
+		return 1
+	}
+	sLen := m.digits()
+	if e > 0 { // `exp` trailing zeros
+		sLen += e
+	} else if e < 0 {
+		if diff := sLen + e; diff < 0 { // leading zeros
+			sLen += -diff
+		}
+		sLen++ // a delimeter
+	}
+	return sLen
+}
+
+func exp(v Value) int {
+	return int(v.hi>>hiMantBits&expMask) - bias
+}
+
+func mant(v Value) uint128 {
+	return uint128{hi: v.hi & hiMantMask, lo: v.lo}
+}
+
+func split(v Value) (mantissa uint128, exponent int) {
+	return mant(v), exp(v)
+}
+
+func fromMantAndExp(m uint128, e int) Value {
+	return Value{hi: uint64(e+bias)<<hiMantBits | m.hi&hiMantMask, lo: m.lo}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
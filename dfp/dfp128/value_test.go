@@ -0,0 +1,316 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp128
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstants(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(35, digitsInMaxMantissa)
+	a.Equal("10384593717069655257060992658440191", maxMantissa.String())
+	a.Equal("10384593717069655257060992658440191e16384", fmt.Sprintf("%v", Max))
+	a.Equal("1e-16383", fmt.Sprintf("%v", Min))
+	a.Equal(zero, fromMantAndExp(uint128{}, minExponent))
+}
+
+func TestFromString(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		err         string
+	}{
+		{"0", "0", ""},
+		{"0.000", "0", ""},
+		{"1", "1", ""},
+		{`"123.456"`, "123.456", ""},
+		{" +1000 ", "1000", ""},
+		{"1e3", "1000", ""},
+		{"1.5e-20", "0.000000000000000000015", ""},
+		{"123456789012345678.123456789012345678", "123456789012345678.1234567890123456", ""},
+		{"0.000000000000000001", "0.000000000000000001", ""},
+		{"1234567890123456789012345678901234", "1234567890123456789012345678901234", ""},
+		{"12345678901234567890123456789012345678", "12345678901234567890123456789012340000", ""},
+		{"1E5", "100000", ""},
+		{"1e-16384", "0", ""},
+		{"", "", "empty input"},
+		{"-1", "", "negative value"},
+		{"1.2.3", "", "parsing failed: unexpected delimeter at pos 4"},
+		{"12a", "", "parsing failed: unexpected symbol 'a' at pos 3"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := FromString(test.s)
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, v.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+	a.Panics(func() { MustFromString("abc") })
+}
+
+func TestFromMantAndExp(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("12.3", FromMantAndExp(123, -1).String())
+	a.Equal("1230", FromMantAndExp(123, 1).String())
+	a.Equal("18446744073709551615", FromUint64(math.MaxUint64).String())
+	v := FromMant128AndExp(1, 0, -20)
+	a.Equal("0.18446744073709551616", v.String())
+	hi, lo := v.Mant128()
+	a.Equal([]uint64{1, 0}, []uint64{hi, lo})
+	a.Equal(int32(-20), v.Exp())
+	a.Equal("10000000000e16384", fmt.Sprintf("%v", FromMantAndExp(1, maxExponent+10)))
+	a.Equal(Max, FromMantAndExp(1, maxExponent+40))
+	a.Equal(zero, FromMantAndExp(1, minExponent-1))
+}
+
+func TestFormat(t *testing.T) {
+	a := assert.New(t)
+	v := MustFromString("1234567890123456789.0123456789")
+	a.Equal("1234567890123456789.0123456789", fmt.Sprintf("%s", v))
+	a.Equal("1234567890123456789.0123456789", fmt.Sprintf("%f", v))
+	a.Equal("12345678901234567890123456789e-10", fmt.Sprintf("%v", v))
+	a.Equal("12345678901234567890123456789e-10", fmt.Sprintf("%e", v))
+	a.Equal("{12345678901234567890123456789, -10}", v.GoString())
+	a.Equal("0", fmt.Sprintf("%v", zero))
+}
+
+func TestJSON(t *testing.T) {
+	a := assert.New(t)
+	defer func(mode int) { dfp.JSONMode = mode }(dfp.JSONMode)
+	tests := []struct {
+		v                      Value
+		str, flt, obj, compact string
+	}{
+		{MustFromString("123.456"), `"123.456"`, `123.456`, `{"m":123456,"e":-3}`, `"123.456"`},
+		{MustFromString("1e100"), `"1` + fmt.Sprintf("%0100d", 0) + `"`, `1` + fmt.Sprintf("%0100d", 0), `{"m":1,"e":100}`, `{"m":1,"e":100}`},
+		{MustFromString("123456789012345678.123456789012345678"), `"123456789012345678.1234567890123456"`, `123456789012345680`,
+			`{"m":1234567890123456781234567890123456,"e":-16}`, `"123456789012345678.1234567890123456"`},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for mode, expected := range map[int]string{
+				dfp.FormatString:     test.str,
+				dfp.FormatFloat:      test.flt,
+				dfp.FormatJSONObject: test.obj,
+				dfp.JSONModeCompact:  test.compact,
+			} {
+				dfp.JSONMode = mode
+				data, err := json.Marshal(test.v)
+				if a.NoError(err) {
+					a.Equal(expected, string(data))
+				}
+				if mode == dfp.FormatFloat {
+					continue
+				}
+				var v Value
+				if a.NoError(json.Unmarshal(data, &v)) {
+					a.True(test.v.Eq(v), "%v != %v", test.v, v)
+				}
+			}
+		})
+	}
+	var v Value
+	a.Error(v.UnmarshalJSON(nil))
+	a.Error(json.Unmarshal([]byte(`{"m":"abc","e":1}`), &v))
+	a.Error(json.Unmarshal([]byte(`"-1"`), &v))
+}
+
+func TestCmp(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b     Value
+		expected int
+	}{
+		{zero, zero, 0},
+		{zero, Min, -1},
+		{Max, Min, 1},
+		{MustFromString("1.5"), FromMantAndExp(150, -2), 0},
+		{MustFromString("1000000000000000.000000000000000001"), MustFromString("1e15"), 1},
+		{MustFromString("0.000000000000000001"), MustFromString("0.000000000000000002"), -1},
+		{fromMantAndExp(uint128{lo: 15}, -1), fromMantAndExp(uint128{lo: 1500}, -3), 0},
+		{fromMantAndExp(uint128{lo: 15}, 30), fromMantAndExp(maxMantissa, -4), 1},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			a.Equal(test.expected, test.a.Cmp(test.b))
+			a.Equal(-test.expected, test.b.Cmp(test.a))
+			a.Equal(test.expected == 0, test.a.Eq(test.b))
+		})
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b, sum, diff string
+		neg             bool
+	}{
+		{"0", "0", "0", "0", false},
+		{"0", "1", "1", "1", true},
+		{"1", "0", "1", "1", false},
+		{"1234567890123456.5", "0.000000000000000001", "1234567890123456.500000000000000001", "1234567890123456.499999999999999999", false},
+		{"123456789012345678.5", "0.000000000000000001", "123456789012345678.5", "123456789012345678.5", false},
+		{"99999999999999999999999999999999.99", "0.01", "100000000000000000000000000000000", "99999999999999999999999999999999.98", false},
+		{"1e16", "1e-16", "10000000000000000.0000000000000001", "9999999999999999.9999999999999999", false},
+		{"1e20", "1e-20", "100000000000000000000", "100000000000000000000", false},
+		{"1", "1e30", "1000000000000000000000000000001", "999999999999999999999999999999", true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v1, v2 := MustFromString(test.a), MustFromString(test.b)
+			a.Equal(test.sum, v1.Add(v2).String())
+			a.Equal(test.sum, v2.Add(v1).String())
+			diff, neg := v1.Sub(v2)
+			a.Equal(test.diff, diff.String())
+			a.Equal(test.neg, neg)
+		})
+	}
+	a.Equal(Max, Max.Add(Max))
+}
+
+func TestMul(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b, expected string
+	}{
+		{"0", "1", "0"},
+		{"1e18", "0.000000000000000001", "1"},
+		{"123456789.123456789", "1000000000", "123456789123456789"},
+		{"123456789012345678.123456789012345678", "123456789012345678.123456789012345678",
+			"15241578753238836558451457271757330"},
+		{"18446744073709551616", "18446744073709551616", "340282366920938463463374607431768200000"},
+		{"0.5", "0.5", "0.25"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v1, v2 := MustFromString(test.a), MustFromString(test.b)
+			a.Equal(test.expected, v1.Mul(v2).String())
+			a.Equal(test.expected, v2.Mul(v1).String())
+		})
+	}
+	a.Equal(Max, Max.Mul(FromUint64(10)))
+	a.Equal(zero, Min.Mul(MustFromString("0.1")))
+}
+
+func TestDivQuo(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b     string
+		prec     int
+		mode     dfp.RoundingMode
+		quo, div string
+	}{
+		{"1", "7", 40, dfp.RoundDown, "0.1428571428571428571428571428571428", "0.1428571428571428571428571428571428"},
+		{"2", "3", 20, dfp.RoundHalfUp, "0.66666666666666666667", "0.6666666666666666666666666666666666"},
+		{"2", "3", 20, dfp.RoundNearest, "0.66666666666666666667", "0.6666666666666666666666666666666666"},
+		{"1", "3", 20, dfp.RoundUp, "0.33333333333333333334", "0.3333333333333333333333333333333333"},
+		{"123456789.123456789123456789", "0.0003", 10, dfp.RoundDown, "411522630411.5226304115", "411522630411.52263041152263"},
+		{"1", "8", 2, dfp.RoundNearest, "0.12", "0.125"},
+		{"1", "8", 2, dfp.RoundHalfUp, "0.13", "0.125"},
+		{"1", "1e30", 10, dfp.RoundDown, "0", "0.000000000000000000000000000001"},
+		{"1", "1e30", 10, dfp.RoundUp, "0.0000000001", "0.000000000000000000000000000001"},
+		{"1e30", "3", -10, dfp.RoundDown, "333333333333333333330000000000", "333333333333333333333333333333.3333"},
+		{"0", "3", 10, dfp.RoundUp, "0", "0"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v1, v2 := MustFromString(test.a), MustFromString(test.b)
+			a.Equal(test.quo, v1.Quo(v2, test.prec, test.mode).String())
+			a.Equal(test.div, v1.Div(v2).String())
+		})
+	}
+	a.Panics(func() { FromUint64(1).Div(zero) })
+	a.Panics(func() { FromUint64(1).Quo(zero, 2, dfp.RoundDown) })
+}
+
+func TestRound(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v                         string
+		prec                      int
+		floor, round, ceil, halfU string
+	}{
+		{"1234567890123456.123456789012345675", 17, "1234567890123456.12345678901234567", "1234567890123456.12345678901234567",
+			"1234567890123456.12345678901234568", "1234567890123456.12345678901234568"},
+		{"1.125", 2, "1.12", "1.12", "1.13", "1.13"},
+		{"1.125", 5, "1.125", "1.125", "1.125", "1.125"},
+		{"1250", -2, "1200", "1200", "1300", "1300"},
+		{"1251", -2, "1200", "1300", "1300", "1300"},
+		{"0.0001", 2, "0", "0", "0.01", "0"},
+		{"15", -3, "0", "0", "1000", "0"},
+		{"0", 2, "0", "0", "0", "0"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v := MustFromString(test.v)
+			a.Equal(test.floor, v.Floor(test.prec).String())
+			a.Equal(test.round, v.Round(test.prec).String())
+			a.Equal(test.ceil, v.Ceil(test.prec).String())
+			a.Equal(test.halfU, v.RoundTo(test.prec, dfp.RoundHalfUp).String())
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	a := assert.New(t)
+	for _, s := range []string{"0", "1", "123.456", "0.0000001", "72057594037927935e128", "1e-127"} {
+		v := dfp.MustFromString(s)
+		v128 := FromValue(v)
+		a.Equal(v.String(), v128.String())
+		back, exact := v128.ToValue()
+		a.True(exact)
+		a.True(v.Eq(back), "%v != %v", v, back)
+	}
+	tests := []struct {
+		s, expected string
+		exact       bool
+	}{
+		{"123456789012345678.123456789012345678", "123456789012345670", false},
+		{"1e200", dfp.Max.String(), false},
+		{"1e-200", "0", false},
+		{"1e128", "1" + fmt.Sprintf("%0128d", 0), true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, exact := MustFromString(test.s).ToValue()
+			a.Equal(test.exact, exact)
+			a.Equal(test.expected, v.String())
+		})
+	}
+}
+
+func TestFloatAndUint(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(0.1, MustFromString("0.1").Float64())
+	a.Equal(1.2345678901234568e+17, MustFromString("123456789012345678.123456789012345678").Float64())
+	a.Equal(math.Inf(1), Max.Float64())
+	a.Equal(0.0, Min.Float64())
+	a.Equal("0.1", MustFromFloat64(0.1).String())
+	a.Equal("123.456", MustFromFloat64(123.456).String())
+	a.Equal("0", MustFromFloat64(0).String())
+	_, err := FromFloat64(-1)
+	a.Error(err)
+	_, err = FromFloat64(math.NaN())
+	a.Error(err)
+	a.Panics(func() { MustFromFloat64(math.Inf(1)) })
+
+	a.Equal(uint64(123), MustFromString("123.999").Uint64())
+	a.Equal(uint64(0), MustFromString("0.999").Uint64())
+	a.Equal(uint64(1000), MustFromString("1e3").Uint64())
+	a.Equal(uint64(math.MaxUint64), MustFromString("18446744073709551615").Uint64())
+	a.Equal(uint64(math.MaxUint64), MustFromString("18446744073709551616").Uint64())
+	a.Equal(uint64(math.MaxUint64), MustFromString("1e100").Uint64())
+	a.Equal(uint64(0), Min.Uint64())
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp32
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/stretchr/testify/assert"
+)
+
+// the tests below check the limits of the 32-bit layout,
+// generic behavior is covered by the generated value_test.go.
+
+func TestConstants(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(uintptr(4), unsafe.Sizeof(Value(0)))
+	a.Equal(8, digitsInMaxMantissa)
+	a.Equal("67108863e32", fmt.Sprintf("%v", Max))
+	a.Equal("1e-31", fmt.Sprintf("%v", Min))
+}
+
+func TestFromString(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+	}{
+		{"1.5e-20", "0.000000000000000000015"},
+		{"12345.6789", "12345.678"},
+		{"67108863", "67108863"},
+		{"67108864", "67108860"},
+		{"1e-32", "0"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := FromString(test.s)
+			if a.NoError(err) {
+				a.Equal(test.expected, v.String(), test.s)
+			}
+		})
+	}
+	a.Equal("18446744000000000000", FromUint64(math.MaxUint64).String())
+}
+
+func TestJSON(t *testing.T) {
+	a := assert.New(t)
+	defer func(mode int) { dfp.JSONMode = mode }(dfp.JSONMode)
+	v := MustFromString("1e30")
+	for mode, expected := range map[int]string{
+		dfp.FormatString:     `"1` + fmt.Sprintf("%030d", 0) + `"`,
+		dfp.FormatFloat:      `1` + fmt.Sprintf("%030d", 0),
+		dfp.FormatJSONObject: `{"m":1,"e":30}`,
+		dfp.JSONModeCompact:  `{"m":1,"e":30}`,
+	} {
+		dfp.JSONMode = mode
+		data, err := json.Marshal(v)
+		if a.NoError(err) {
+			a.Equal(expected, string(data))
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b, sum, diff, prod, quo string
+	}{
+		{"1", "3", "4", "2", "3", "0.3333333"},
+		{"9999999", "1", "10000000", "9999998", "9999999", "9999999"},
+		{"6710886.3", "0.1", "6710886", "6710886.2", "671088.63", "67108860"},
+		{"1e20", "1e-5", "100000000000000000000", "100000000000000000000", "1000000000000000", "1e25"},
+		{"12345678", "12345678", "24691356", "0", "152415760000000", "1"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v1, v2 := MustFromString(test.a), MustFromString(test.b)
+			a.Equal(test.sum, v1.Add(v2).String())
+			diff, _ := v1.Sub(v2)
+			a.Equal(test.diff, diff.String())
+			a.Equal(test.prod, v1.Mul(v2).String())
+			a.True(MustFromString(test.quo).Eq(v1.Div(v2)), "%v != %v", test.quo, v1.Div(v2))
+		})
+	}
+	a.Equal("3333300000", MustFromString("1e10").Quo(FromUint64(3), -5, dfp.RoundDown).String())
+	a.Equal("0.001", FromUint64(1).Quo(MustFromString("1e20"), 3, dfp.RoundUp).String())
+}
+
+func TestConversions(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		exact       bool
+	}{
+		{"67108863e32", "67108863e32", true},
+		{"1e-31", "1e-31", true},
+		{"12345.6789", "12345.678", false},
+		{"1e40", "67108863e32", false},
+		{"1e-40", "0", false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, exact := FromValue(dfp.MustFromString(test.s))
+			a.Equal(test.exact, exact)
+			a.True(MustFromString(test.expected).Eq(v), "%v != %v", test.expected, v)
+		})
+	}
+	a.Equal(6.7108863e39, Max.Float64())
+	a.Equal("3.1415926", MustFromFloat64(math.Pi).String())
+	a.Equal(uint64(math.MaxUint64), Max.Uint64())
+}
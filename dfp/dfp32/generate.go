@@ -0,0 +1,5 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp32
+
+//go:generate go run ../../cmd/dfpgen -pkg dfp32 -bits 32 -exp 6
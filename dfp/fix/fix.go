@@ -0,0 +1,167 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package fix implements parsing and formatting of FIX protocol price and quantity fields.
+// FIX floats are ASCII decimals without exponents, quotes, grouping, or a plus sign, like "1234.50".
+// Parsing and formatting into a preallocated buffer do not allocate.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	// SOH is the FIX field delimiter.
+	SOH = 0x01
+
+	// maxDigits is the number of decimal digits, that always fit uint64.
+	maxDigits = 19
+)
+
+// Parse parses a FIX float field value, like the value of tag 44 (Price) or 38 (OrderQty).
+// It accepts digits with an optional decimal point, and rejects signs, exponents, quotes, and spaces.
+// If the number cannot be precisely represented, the least significant digits will be truncated.
+func Parse(field []byte) (dfp.Value, error) {
+	if len(field) == 0 {
+		return dfp.Value(0), fmt.Errorf("empty input")
+	}
+	var mant uint64
+	var e, digits int
+	pointPos := -1
+	for i, c := range field {
+		switch {
+		case c >= '0' && c <= '9':
+			if mant == 0 && c == '0' {
+				if pointPos >= 0 {
+					e--
+				}
+				continue
+			}
+			if digits < maxDigits {
+				mant = mant*10 + uint64(c-'0')
+				digits++
+				if pointPos >= 0 {
+					e--
+				}
+			} else if pointPos < 0 {
+				e++
+			}
+		case c == '.':
+			if pointPos >= 0 {
+				return dfp.Value(0), fmt.Errorf("unexpected delimeter at pos %d", i+1)
+			}
+			pointPos = i
+		default:
+			return dfp.Value(0), fmt.Errorf("unexpected symbol %q at pos %d", c, i+1)
+		}
+	}
+	if pointPos >= 0 && len(field) == 1 {
+		return dfp.Value(0), fmt.Errorf("no digits")
+	}
+	if mant == 0 {
+		return dfp.Value(0), nil
+	}
+	return dfp.FromMantAndExp(mant, int32(e)), nil
+}
+
+// Field returns the value of the first field with given tag in a SOH-delimited FIX message.
+// The result is a subslice of msg. Returns false, if there is no such field.
+func Field(msg []byte, tag int) ([]byte, bool) {
+	for len(msg) > 0 {
+		end := bytes.IndexByte(msg, SOH)
+		if end < 0 {
+			end = len(msg)
+		}
+		if eq := bytes.IndexByte(msg[:end], '='); eq > 0 && atoi(msg[:eq]) == tag {
+			return msg[eq+1 : end], true
+		}
+		if end == len(msg) {
+			break
+		}
+		msg = msg[end+1:]
+	}
+	return nil, false
+}
+
+// ParseField finds a field with given tag in a SOH-delimited FIX message, and parses its value.
+// Returns false, if there is no such field.
+func ParseField(msg []byte, tag int) (v dfp.Value, found bool, err error) {
+	field, found := Field(msg, tag)
+	if !found {
+		return v, false, nil
+	}
+	v, err = Parse(field)
+	return v, true, err
+}
+
+// Append appends a FIX float representation of the value to dst, and returns the extended buffer.
+// Trailing zeros of the fractional part are removed, but at least minDecimals decimal places are written,
+// so that 1.5 is formatted as "1.50" for minDecimals = 2, and as "1.5" for minDecimals = 0.
+func Append(dst []byte, v dfp.Value, minDecimals int) []byte {
+	v = v.Normalized()
+	m, e := v.MantUint64(), int(v.Exp())
+	if m == 0 {
+		dst = append(dst, '0')
+		return appendZeros(dst, 0, minDecimals)
+	}
+	if e >= 0 {
+		dst = strconv.AppendUint(dst, m, 10)
+		for i := 0; i < e; i++ {
+			dst = append(dst, '0')
+		}
+		return appendZeros(dst, 0, minDecimals)
+	}
+	decimals := -e
+	start := len(dst)
+	dst = strconv.AppendUint(dst, m, 10)
+	if digits := len(dst) - start; digits <= decimals {
+		// 0.000ddd
+		dst = append(dst[:start], "0."...)
+		for i := 0; i < decimals-digits; i++ {
+			dst = append(dst, '0')
+		}
+		dst = strconv.AppendUint(dst, m, 10)
+	} else {
+		// ddd.ddd
+		dst = append(dst, 0)
+		pos := len(dst) - 1 - decimals
+		copy(dst[pos+1:], dst[pos:])
+		dst[pos] = '.'
+	}
+	return appendZeros(dst, decimals, minDecimals)
+}
+
+// Format returns a FIX float representation of the value. See Append for details.
+func Format(v dfp.Value, minDecimals int) string {
+	var buf [32]byte
+	return string(Append(buf[:0], v, minDecimals))
+}
+
+// appendZeros appends zeros, so that the number with given decimal places had at least minDecimals decimal places.
+func appendZeros(dst []byte, decimals, minDecimals int) []byte {
+	if decimals >= minDecimals {
+		return dst
+	}
+	if decimals == 0 {
+		dst = append(dst, '.')
+	}
+	for i := decimals; i < minDecimals; i++ {
+		dst = append(dst, '0')
+	}
+	return dst
+}
+
+// atoi returns a non-negative number for a string of digits, or -1.
+func atoi(b []byte) int {
+	var n int
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return -1
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package fix
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func TestParse(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		err         string
+	}{
+		{"0", "0", ""},
+		{"000", "0", ""},
+		{"0.000", "0", ""},
+		{"1", "1", ""},
+		{"1.", "1", ""},
+		{".5", "0.5", ""},
+		{"00023.23", "23.23", ""},
+		{"1234.5000", "1234.5", ""},
+		{"0.05", "0.05", ""},
+		{"100", "100", ""},
+		{"1000000000000000000000", "1000000000000000000000", ""},
+		{"12345678901234567890123", "12345678901234567000000", ""},
+		{"1.2345678901234567890123", "1.2345678901234567", ""},
+		{"0.00000000000000000000012345", "0.00000000000000000000012345", ""},
+		{"", "", "empty input"},
+		{".", "", "no digits"},
+		{"1.2.3", "", "unexpected delimeter at pos 4"},
+		{"-1", "", "unexpected symbol '-' at pos 1"},
+		{"+1", "", "unexpected symbol '+' at pos 1"},
+		{"1e3", "", "unexpected symbol 'e' at pos 2"},
+		{"\"1\"", "", "unexpected symbol '\"' at pos 1"},
+		{" 1", "", "unexpected symbol ' ' at pos 1"},
+		{"1,000", "", "unexpected symbol ',' at pos 2"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := Parse([]byte(test.s))
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, v.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	a := assert.New(t)
+	msg := []byte(strings.Replace("8=FIX.4.4|9=60|35=D|55=EUR/USD|44=1.10250|38=1000000|10=123|", "|", "\x01", -1))
+	field, found := Field(msg, 44)
+	a.True(found)
+	a.Equal("1.10250", string(field))
+	field, found = Field(msg, 8)
+	a.True(found)
+	a.Equal("FIX.4.4", string(field))
+	_, found = Field(msg, 4)
+	a.False(found)
+	field, found = Field(msg[:len(msg)-1], 10)
+	a.True(found)
+	a.Equal("123", string(field))
+
+	v, found, err := ParseField(msg, 38)
+	a.NoError(err)
+	a.True(found)
+	a.Equal("1000000", v.String())
+	_, found, err = ParseField(msg, 99)
+	a.NoError(err)
+	a.False(found)
+	_, found, err = ParseField(msg, 55)
+	a.True(found)
+	a.EqualError(err, "unexpected symbol 'E' at pos 1")
+}
+
+func TestFormat(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v           string
+		minDecimals int
+		expected    string
+	}{
+		{"0", 0, "0"},
+		{"0", 2, "0.00"},
+		{"1.5", 0, "1.5"},
+		{"1.5", 2, "1.50"},
+		{"1.2345", 2, "1.2345"},
+		{"100", 0, "100"},
+		{"100", 2, "100.00"},
+		{"1e20", 0, "100000000000000000000"},
+		{"0.00012", 0, "0.00012"},
+		{"0.00012", 6, "0.000120"},
+		{"123.456", 3, "123.456"},
+		{"1.50000", 1, "1.5"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v := dfp.MustFromString(test.v)
+			a.Equal(test.expected, Format(v, test.minDecimals))
+			a.Equal("44="+test.expected, string(Append([]byte("44="), v, test.minDecimals)))
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v := dfp.FromMantAndExp(uint64(rnd.Int63n(1<<56)), int32(rnd.Intn(60)-40))
+		s := Format(v, rnd.Intn(5))
+		a.NotContains(s, "e")
+		parsed, err := Parse([]byte(s))
+		if !a.NoError(err) || !a.True(v.Eq(parsed), "%v != %v", v, parsed) {
+			break
+		}
+		a.True(v.Eq(dfp.MustFromString(s)))
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	a := assert.New(t)
+	field := []byte("1234.5678")
+	a.Equal(0.0, testing.AllocsPerRun(100, func() {
+		Parse(field)
+	}))
+	v, buf := dfp.MustFromString("1234.5678"), make([]byte, 0, 64)
+	a.Equal(0.0, testing.AllocsPerRun(100, func() {
+		Append(buf[:0], v, 6)
+	}))
+}
+
+func BenchmarkParse(b *testing.B) {
+	field := []byte("1234.5678")
+	for i := 0; i < b.N; i++ {
+		Parse(field)
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	v, buf := dfp.MustFromString("1234.5678"), make([]byte, 0, 64)
+	for i := 0; i < b.N; i++ {
+		Append(buf[:0], v, 2)
+	}
+}
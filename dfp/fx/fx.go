@@ -0,0 +1,201 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package fx implements currency conversions with decimal floating-point exchange rates.
+// Inverse rates are calculated with dfp.Value.Quo and cross rates with dfp.Accumulator,
+// so both are exact up to given precision.
+// All the results are rounded half up.
+package fx
+
+import (
+	"fmt"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+var (
+	one = dfp.FromUint64(1)
+)
+
+// Rate is an exchange rate, so that 1 unit of Base costs Value units of Quote.
+type Rate struct {
+	Base, Quote string
+	Value       dfp.Value
+}
+
+// String returns a string representation of the rate, like "EUR/USD 1.1".
+func (r Rate) String() string {
+	return r.Base + "/" + r.Quote + " " + r.Value.String()
+}
+
+// Convert converts an amount in Base currency into Quote currency.
+func (r Rate) Convert(amount dfp.Value) dfp.Value {
+	return amount.Mul(r.Value)
+}
+
+// Invert returns Quote/Base rate rounded to prec decimal places. If the rate is zero, Invert panics.
+func (r Rate) Invert(prec int) Rate {
+	return Rate{Base: r.Quote, Quote: r.Base, Value: one.Quo(r.Value, prec, dfp.RoundHalfUp)}
+}
+
+// Cross calculates a cross rate for two rates sharing a common currency.
+// The base of the result is the non-common currency of a, the quote is the non-common currency of b.
+// For example, EUR/USD and GBP/USD give EUR/GBP, USD/JPY and EUR/USD give JPY/EUR.
+// The product of the rates, if needed, is calculated exactly, and the result is rounded once to prec decimal places.
+func Cross(a, b Rate, prec int) (Rate, error) {
+	if a.Value.IsZero() || b.Value.IsZero() {
+		return Rate{}, fmt.Errorf("zero rate")
+	}
+	var result Rate
+	var num, den dfp.Accumulator
+	switch {
+	case a.Quote == b.Quote: // X/C, Y/C
+		result.Base, result.Quote = a.Base, b.Base
+		num.Add(a.Value)
+		den.Add(b.Value)
+	case a.Quote == b.Base: // X/C, C/Y
+		result.Base, result.Quote = a.Base, b.Quote
+		num.AddMul(a.Value, b.Value)
+		den.Add(one)
+	case a.Base == b.Quote: // C/X, Y/C
+		result.Base, result.Quote = a.Quote, b.Base
+		num.Add(one)
+		den.AddMul(a.Value, b.Value)
+	case a.Base == b.Base: // C/X, C/Y
+		result.Base, result.Quote = a.Quote, b.Quote
+		num.Add(b.Value)
+		den.Add(a.Value)
+	default:
+		return Rate{}, fmt.Errorf("rates %s/%s and %s/%s have no common currency", a.Base, a.Quote, b.Base, b.Quote)
+	}
+	if result.Base == result.Quote {
+		return Rate{}, fmt.Errorf("rates %s/%s and %s/%s have the same currencies", a.Base, a.Quote, b.Base, b.Quote)
+	}
+	result.Value, _, _ = num.Quo(&den, prec, dfp.RoundHalfUp)
+	return result, nil
+}
+
+// RateTable holds a set of exchange rates and finds conversion paths between currencies.
+// It is not safe for concurrent use.
+type RateTable struct {
+	rates map[pair]dfp.Value
+	// adj holds currencies, that have a direct rate with the given one, in the order they were added.
+	adj map[string][]string
+}
+
+type pair struct {
+	base, quote string
+}
+
+// step is a single conversion step. If inverse is true, the amount is divided by the rate.
+type step struct {
+	rate    Rate
+	inverse bool
+}
+
+// NewRateTable returns a new table containing given rates.
+func NewRateTable(rates ...Rate) *RateTable {
+	t := &RateTable{
+		rates: make(map[pair]dfp.Value),
+		adj:   make(map[string][]string),
+	}
+	for _, r := range rates {
+		t.Add(r)
+	}
+	return t
+}
+
+// Add adds a rate to the table, replacing the rate for the same currency pair, if any.
+// Zero rates are ignored.
+func (t *RateTable) Add(r Rate) {
+	if r.Value.IsZero() || r.Base == r.Quote {
+		return
+	}
+	p := pair{base: r.Base, quote: r.Quote}
+	if _, found := t.rates[p]; !found {
+		if _, found := t.rates[pair{base: r.Quote, quote: r.Base}]; !found {
+			t.adj[r.Base] = append(t.adj[r.Base], r.Quote)
+			t.adj[r.Quote] = append(t.adj[r.Quote], r.Base)
+		}
+	}
+	t.rates[p] = r.Value
+}
+
+// Path returns the shortest chain of rates, that allows to convert from one currency to another.
+func (t *RateTable) Path(from, to string) ([]Rate, error) {
+	steps, err := t.path(from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Rate, len(steps))
+	for i, s := range steps {
+		result[i] = s.rate
+	}
+	return result, nil
+}
+
+// Rate returns base/quote rate rounded to prec decimal places.
+// If there is no direct rate, it is calculated from the shortest chain of rates.
+func (t *RateTable) Rate(base, quote string, prec int) (Rate, error) {
+	v, err := t.Convert(one, base, quote, prec)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{Base: base, Quote: quote, Value: v}, nil
+}
+
+// Convert converts an amount from one currency into another using the shortest chain of rates.
+// The rates along the chain are multiplied with dfp.Value.Mul, so the least significant digits of intermediate
+// products, that do not fit the mantissa, are truncated. The result is then rounded to prec decimal places.
+func (t *RateTable) Convert(amount dfp.Value, from, to string, prec int) (dfp.Value, error) {
+	steps, err := t.path(from, to)
+	if err != nil {
+		return dfp.Value(0), err
+	}
+	num, den := amount, one
+	for _, s := range steps {
+		if s.inverse {
+			den = den.Mul(s.rate.Value)
+		} else {
+			num = num.Mul(s.rate.Value)
+		}
+	}
+	return num.Quo(den, prec, dfp.RoundHalfUp), nil
+}
+
+// path finds the shortest conversion path using breadth-first search.
+func (t *RateTable) path(from, to string) ([]step, error) {
+	if from == to {
+		return nil, nil
+	}
+	prev := map[string]string{from: from}
+	queue := []string{from}
+	for len(queue) > 0 && len(prev[to]) == 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range t.adj[cur] {
+			if _, found := prev[next]; !found {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(prev[to]) == 0 {
+		return nil, fmt.Errorf("no conversion path from %s to %s", from, to)
+	}
+	var steps []step
+	for cur := to; cur != from; cur = prev[cur] {
+		steps = append(steps, t.step(prev[cur], cur))
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps, nil
+}
+
+// step returns a conversion step between two currencies, that have a direct rate.
+func (t *RateTable) step(from, to string) step {
+	if v, found := t.rates[pair{base: from, quote: to}]; found {
+		return step{rate: Rate{Base: from, Quote: to, Value: v}}
+	}
+	return step{rate: Rate{Base: to, Quote: from, Value: t.rates[pair{base: to, quote: from}]}, inverse: true}
+}
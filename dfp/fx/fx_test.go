@@ -0,0 +1,126 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package fx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func rate(base, quote, v string) Rate {
+	return Rate{Base: base, Quote: quote, Value: dfp.MustFromString(v)}
+}
+
+func TestRate(t *testing.T) {
+	a := assert.New(t)
+	r := rate("EUR", "USD", "1.1")
+	a.Equal("EUR/USD 1.1", r.String())
+	a.Equal("110", r.Convert(dfp.MustFromString("100")).String())
+	a.Equal("USD/EUR 0.90909091", r.Invert(8).String())
+	a.Equal("USD/EUR 0.9090909090909091", r.Invert(100).String())
+	a.Equal("JPY/USD 0.00909", rate("USD", "JPY", "110").Invert(5).String())
+	a.Panics(func() { rate("USD", "JPY", "0").Invert(5) })
+}
+
+func TestCross(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b     Rate
+		expected string
+		err      string
+	}{
+		{rate("EUR", "USD", "1.1"), rate("GBP", "USD", "1.25"), "EUR/GBP 0.88", ""},
+		{rate("EUR", "USD", "1.1"), rate("USD", "JPY", "110"), "EUR/JPY 121", ""},
+		{rate("USD", "JPY", "110"), rate("EUR", "USD", "1.1"), "JPY/EUR 0.00826446", ""},
+		{rate("USD", "JPY", "110"), rate("USD", "CHF", "0.92"), "JPY/CHF 0.00836364", ""},
+		{rate("EUR", "USD", "1.1"), rate("GBP", "JPY", "137.5"), "", "rates EUR/USD and GBP/JPY have no common currency"},
+		{rate("EUR", "USD", "1.1"), rate("EUR", "USD", "1.2"), "", "rates EUR/USD and EUR/USD have the same currencies"},
+		{rate("EUR", "USD", "0"), rate("GBP", "USD", "1.25"), "", "zero rate"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			r, err := Cross(test.a, test.b, 8)
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, r.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+	// the product of the rates has more significant digits, than a value can hold.
+	r, err := Cross(rate("EUR", "USD", "1.234567891"), rate("USD", "JPY", "123.4567891"), 14)
+	if a.NoError(err) {
+		a.Equal("EUR/JPY 152.41578774881879", r.String())
+	}
+	r, err = Cross(rate("USD", "JPY", "123.4567891"), rate("EUR", "USD", "1.234567891"), 19)
+	if a.NoError(err) {
+		a.Equal("JPY/EUR 0.0065610001087813814", r.String())
+	}
+}
+
+func TestRateTable(t *testing.T) {
+	a := assert.New(t)
+	table := NewRateTable(
+		rate("EUR", "USD", "1.1"),
+		rate("USD", "JPY", "110"),
+		rate("GBP", "USD", "1.25"),
+		rate("EUR", "CHF", "1.08"),
+		rate("XAU", "XAG", "0"),
+	)
+	tests := []struct {
+		from, to string
+		amount   string
+		prec     int
+		expected string
+		path     []string
+		err      string
+	}{
+		{"EUR", "EUR", "100", 2, "100", nil, ""},
+		{"EUR", "USD", "100", 2, "110", []string{"EUR/USD 1.1"}, ""},
+		{"USD", "EUR", "100", 2, "90.91", []string{"EUR/USD 1.1"}, ""},
+		{"GBP", "JPY", "1", 4, "137.5", []string{"GBP/USD 1.25", "USD/JPY 110"}, ""},
+		{"JPY", "CHF", "1", 6, "0.008926", []string{"USD/JPY 110", "EUR/USD 1.1", "EUR/CHF 1.08"}, ""},
+		{"CHF", "GBP", "1000", 2, "814.81", []string{"EUR/CHF 1.08", "EUR/USD 1.1", "GBP/USD 1.25"}, ""},
+		{"EUR", "AUD", "1", 2, "", nil, "no conversion path from EUR to AUD"},
+		{"XAU", "XAG", "1", 2, "", nil, "no conversion path from XAU to XAG"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := table.Convert(dfp.MustFromString(test.amount), test.from, test.to, test.prec)
+			if len(test.err) > 0 {
+				a.EqualError(err, test.err)
+				return
+			}
+			if !a.NoError(err) {
+				return
+			}
+			a.Equal(test.expected, v.String())
+			path, err := table.Path(test.from, test.to)
+			if a.NoError(err) && a.Len(path, len(test.path)) {
+				for i, r := range path {
+					a.Equal(test.path[i], r.String())
+				}
+			}
+		})
+	}
+	r, err := table.Rate("GBP", "EUR", 6)
+	if a.NoError(err) {
+		a.Equal("GBP/EUR 1.136364", r.String())
+	}
+	table.Add(rate("GBP", "EUR", "1.14"))
+	r, err = table.Rate("GBP", "EUR", 6)
+	if a.NoError(err) {
+		a.Equal("GBP/EUR 1.14", r.String())
+	}
+	table.Add(rate("EUR", "GBP", "0.88"))
+	r, err = table.Rate("EUR", "GBP", 6)
+	if a.NoError(err) {
+		a.Equal("EUR/GBP 0.88", r.String())
+	}
+}
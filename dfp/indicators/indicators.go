@@ -0,0 +1,259 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package indicators implements streaming technical indicators over decimal floating-point prices.
+// Indicators are updated with Add, and return results rounded to the precision and the rounding mode,
+// which they were created with. Sums are calculated exactly with dfp.Accumulator,
+// so the results do not drift, no matter how many values were added.
+package indicators
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/avdva/numeric/dfp/stats"
+)
+
+var (
+	// fullPrec is the number of decimal places, which is enough for any dfp.Value.
+	fullPrec = -int(dfp.Min.Exp())
+)
+
+// Sample is a trade: a price and a quantity at some moment.
+type Sample struct {
+	Time  time.Time
+	Price dfp.Value
+	Qty   dfp.Value
+}
+
+// SMA is a simple moving average of the last period prices.
+type SMA struct {
+	w     window
+	sum   dfp.Accumulator
+	count dfp.Accumulator
+	prec  int
+	mode  dfp.RoundingMode
+}
+
+// NewSMA returns a simple moving average for given period.
+func NewSMA(period, prec int, mode dfp.RoundingMode) (*SMA, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("bad period: %d", period)
+	}
+	s := &SMA{w: newWindow(period), prec: prec, mode: mode}
+	s.count.Add(dfp.FromUint64(uint64(period)))
+	return s, nil
+}
+
+// Add adds a price.
+func (s *SMA) Add(price dfp.Value) {
+	if evicted, ok := s.w.push(price); ok {
+		s.sum.Sub(evicted)
+	}
+	s.sum.Add(price)
+}
+
+// Value returns the average. It returns false, if less than period prices were added.
+func (s *SMA) Value() (dfp.Value, bool) {
+	if !s.w.full {
+		return 0, false
+	}
+	v, _, _ := s.sum.Quo(&s.count, s.prec, s.mode)
+	return v, true
+}
+
+// EMA is an exponential moving average with smoothing factor 2 / (period + 1).
+// It starts with a simple average of the first period prices.
+// The average is kept with the full precision of the mantissa, and it is rounded only in Value.
+type EMA struct {
+	seed  *SMA
+	value dfp.Value
+	ready bool
+	// weight is period - 1, and divisor is period + 1.
+	weight  dfp.Value
+	divisor dfp.Accumulator
+	prec    int
+	mode    dfp.RoundingMode
+}
+
+// NewEMA returns an exponential moving average for given period.
+func NewEMA(period, prec int, mode dfp.RoundingMode) (*EMA, error) {
+	seed, err := NewSMA(period, fullPrec, dfp.RoundNearest)
+	if err != nil {
+		return nil, err
+	}
+	e := &EMA{seed: seed, weight: dfp.FromUint64(uint64(period - 1)), prec: prec, mode: mode}
+	e.divisor.Add(dfp.FromUint64(uint64(period + 1)))
+	return e, nil
+}
+
+// Add adds a price.
+func (e *EMA) Add(price dfp.Value) {
+	if !e.ready {
+		e.seed.Add(price)
+		e.value, e.ready = e.seed.Value()
+		return
+	}
+	// ema = (2 * price + (period - 1) * ema) / (period + 1)
+	var acc dfp.Accumulator
+	acc.AddMul(price, dfp.FromUint64(2))
+	acc.AddMul(e.value, e.weight)
+	e.value, _, _ = acc.Quo(&e.divisor, fullPrec, dfp.RoundNearest)
+}
+
+// Value returns the average. It returns false, if less than period prices were added.
+func (e *EMA) Value() (dfp.Value, bool) {
+	if !e.ready {
+		return 0, false
+	}
+	return e.value.RoundTo(e.prec, e.mode), true
+}
+
+// RollingMinMax tracks the minimum and the maximum of the last period values.
+// Both Add and Min/Max take amortized constant time.
+type RollingMinMax struct {
+	period   int64
+	count    int64
+	min, max []indexed
+}
+
+// indexed is a value and its sequence number.
+type indexed struct {
+	v dfp.Value
+	i int64
+}
+
+// NewRollingMinMax returns a rolling minimum and maximum for given period.
+func NewRollingMinMax(period int) (*RollingMinMax, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("bad period: %d", period)
+	}
+	return &RollingMinMax{period: int64(period)}, nil
+}
+
+// Add adds a value.
+func (r *RollingMinMax) Add(v dfp.Value) {
+	r.min = pushMonotonic(r.min, indexed{v: v, i: r.count}, r.period, 1)
+	r.max = pushMonotonic(r.max, indexed{v: v, i: r.count}, r.period, -1)
+	r.count++
+}
+
+// Min returns the minimum. It returns false, if less than period values were added.
+func (r *RollingMinMax) Min() (dfp.Value, bool) {
+	if r.count < r.period {
+		return 0, false
+	}
+	return r.min[0].v, true
+}
+
+// Max returns the maximum. It returns false, if less than period values were added.
+func (r *RollingMinMax) Max() (dfp.Value, bool) {
+	if r.count < r.period {
+		return 0, false
+	}
+	return r.max[0].v, true
+}
+
+// pushMonotonic adds an item to a queue, where v * sign increases, and removes the items,
+// which are out of the window.
+func pushMonotonic(q []indexed, it indexed, period int64, sign int) []indexed {
+	for len(q) > 0 && q[len(q)-1].v.Cmp(it.v)*sign >= 0 {
+		q = q[:len(q)-1]
+	}
+	q = append(q, it)
+	for q[0].i <= it.i-period {
+		q = q[1:]
+	}
+	return q
+}
+
+// Bollinger calculates Bollinger bands: a simple moving average of the last period prices,
+// and the bands k population standard deviations above and below it.
+// Like SMA, it keeps exact sums of the prices and their squares, so Value does not iterate over the window.
+type Bollinger struct {
+	w     window
+	sum   dfp.Accumulator
+	sumSq dfp.Accumulator
+	count dfp.Accumulator
+	k     dfp.Value
+	prec  int
+	mode  dfp.RoundingMode
+}
+
+// NewBollinger returns Bollinger bands for given period and width k.
+func NewBollinger(period int, k dfp.Value, prec int, mode dfp.RoundingMode) (*Bollinger, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("bad period: %d", period)
+	}
+	b := &Bollinger{w: newWindow(period), k: k, prec: prec, mode: mode}
+	b.count.Add(dfp.FromUint64(uint64(period)))
+	return b, nil
+}
+
+// Add adds a price.
+func (b *Bollinger) Add(price dfp.Value) {
+	if evicted, ok := b.w.push(price); ok {
+		b.sum.Sub(evicted)
+		b.sumSq.SubMul(evicted, evicted)
+	}
+	b.sum.Add(price)
+	b.sumSq.AddMul(price, price)
+}
+
+// Value returns the middle, the upper and the lower bands. The lower band is zero, if it would be negative.
+// It returns false, if less than period prices were added.
+// The middle band is exact up to the precision. The mean and the standard deviation used for the other bands
+// are calculated with the full precision of the mantissa, and the bands are rounded once.
+func (b *Bollinger) Value() (middle, upper, lower dfp.Value, ok bool) {
+	if !b.w.full {
+		return 0, 0, 0, false
+	}
+	middle, _, _ = b.sum.Quo(&b.count, b.prec, b.mode)
+	mean, _, _ := b.sum.Quo(&b.count, fullPrec, dfp.RoundNearest)
+	// variance = sumSq / n - (sum / n)^2
+	n := new(big.Rat).SetInt64(int64(b.w.period))
+	avg := b.sum.BigRat()
+	avg.Quo(avg, n)
+	variance := b.sumSq.BigRat()
+	variance.Quo(variance, n).Sub(variance, avg.Mul(avg, avg))
+	stdDev := stats.Sqrt(variance, fullPrec, dfp.RoundNearest)
+	var band dfp.Accumulator
+	band.Add(mean)
+	band.AddMul(stdDev, b.k)
+	upper, _, _ = band.Result(b.prec, b.mode)
+	band.Reset()
+	band.Add(mean)
+	band.SubMul(stdDev, b.k)
+	if v, neg, _ := band.Result(b.prec, b.mode); !neg {
+		lower = v
+	}
+	return middle, upper, lower, true
+}
+
+// window is a ring buffer holding the last values.
+type window struct {
+	values []dfp.Value
+	next   int
+	full   bool
+	period int
+}
+
+func newWindow(period int) window {
+	return window{values: make([]dfp.Value, 0, period), period: period}
+}
+
+// push adds a value, and returns the value, which was evicted, if the window was full.
+func (w *window) push(v dfp.Value) (evicted dfp.Value, ok bool) {
+	if !w.full {
+		w.values = append(w.values, v)
+		w.full = len(w.values) == w.period
+		return 0, false
+	}
+	evicted = w.values[w.next]
+	w.values[w.next] = v
+	if w.next++; w.next == w.period {
+		w.next = 0
+	}
+	return evicted, true
+}
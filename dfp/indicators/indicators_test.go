@@ -0,0 +1,168 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package indicators
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+	"github.com/avdva/numeric/dfp/stats"
+)
+
+func values(strs ...string) []dfp.Value {
+	result := make([]dfp.Value, len(strs))
+	for i, s := range strs {
+		result[i] = dfp.MustFromString(s)
+	}
+	return result
+}
+
+func TestSMA(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewSMA(0, 2, dfp.RoundDown)
+	a.EqualError(err, "bad period: 0")
+
+	sma, err := NewSMA(3, 2, dfp.RoundHalfUp)
+	a.NoError(err)
+	expected := []string{"", "", "2", "2.33", "3.67", "4.5"}
+	for i, v := range values("1", "2", "3", "2", "6", "5.5") {
+		sma.Add(v)
+		result, ok := sma.Value()
+		a.Equal(expected[i] != "", ok, "%d", i)
+		if ok {
+			a.Equal(expected[i], result.String(), "%d", i)
+		}
+	}
+}
+
+func TestEMA(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewEMA(-1, 2, dfp.RoundDown)
+	a.EqualError(err, "bad period: -1")
+
+	ema, err := NewEMA(3, 2, dfp.RoundHalfUp)
+	a.NoError(err)
+	// the smoothing factor is 0.5, and the first value is the average of 1, 2, 3.
+	expected := []string{"", "", "2", "3", "4", "4", "4.5", "4.25", "4.13"}
+	for i, v := range values("1", "2", "3", "4", "5", "4", "5", "4", "4") {
+		ema.Add(v)
+		result, ok := ema.Value()
+		a.Equal(expected[i] != "", ok, "%d", i)
+		if ok {
+			a.Equal(expected[i], result.String(), "%d", i)
+		}
+	}
+	// 4.125 is kept precisely.
+	ema.Add(dfp.MustFromString("4"))
+	result, _ := ema.Value()
+	a.Equal("4.06", result.String())
+}
+
+func TestRollingMinMax(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewRollingMinMax(0)
+	a.EqualError(err, "bad period: 0")
+
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for _, period := range []int{1, 2, 5, 50} {
+		r, err := NewRollingMinMax(period)
+		a.NoError(err)
+		var all []dfp.Value
+		for i := 0; i < 1000; i++ {
+			v := dfp.FromMantAndExp(uint64(rnd.Intn(100)), -int32(rnd.Intn(3)))
+			r.Add(v)
+			all = append(all, v)
+			min, okMin := r.Min()
+			max, okMax := r.Max()
+			a.Equal(len(all) >= period, okMin)
+			a.Equal(len(all) >= period, okMax)
+			if len(all) < period {
+				continue
+			}
+			expectedMin, expectedMax := all[len(all)-period], all[len(all)-period]
+			for _, v := range all[len(all)-period:] {
+				if v.Cmp(expectedMin) < 0 {
+					expectedMin = v
+				}
+				if v.Cmp(expectedMax) > 0 {
+					expectedMax = v
+				}
+			}
+			a.True(expectedMin.Eq(min), "%d: %v != %v", period, expectedMin, min)
+			a.True(expectedMax.Eq(max), "%d: %v != %v", period, expectedMax, max)
+		}
+		a.True(len(r.min) <= period && len(r.max) <= period)
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	a := assert.New(t)
+	_, err := NewBollinger(0, dfp.FromUint64(2), 2, dfp.RoundDown)
+	a.EqualError(err, "bad period: 0")
+
+	b, err := NewBollinger(8, dfp.FromUint64(2), 2, dfp.RoundHalfUp)
+	a.NoError(err)
+	for _, v := range values("2", "4", "4", "4", "5", "5", "7") {
+		b.Add(v)
+	}
+	_, _, _, ok := b.Value()
+	a.False(ok)
+	b.Add(dfp.MustFromString("9"))
+	middle, upper, lower, ok := b.Value()
+	a.True(ok)
+	a.Equal("5", middle.String())
+	a.Equal("9", upper.String())
+	a.Equal("1", lower.String())
+
+	// the window is 4, 4, 4, 5, 5, 7, 9, 1: the mean is 4.875, and the deviation is 2.2047...
+	b.Add(dfp.MustFromString("1"))
+	middle, upper, lower, _ = b.Value()
+	a.Equal("4.88", middle.String())
+	a.Equal("9.28", upper.String())
+	a.Equal("0.47", lower.String())
+
+	b, _ = NewBollinger(2, dfp.FromUint64(3), 2, dfp.RoundHalfUp)
+	b.Add(dfp.MustFromString("1"))
+	b.Add(dfp.MustFromString("3"))
+	_, upper, lower, _ = b.Value()
+	a.Equal("5", upper.String())
+	a.Equal("0", lower.String())
+
+	// the running sums must give the same results, as the statistics over the window.
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	k := dfp.MustFromString("2.5")
+	b, _ = NewBollinger(5, k, 4, dfp.RoundHalfUp)
+	var prices []dfp.Value
+	for i := 0; i < 1000; i++ {
+		prices = append(prices, dfp.FromMantAndExp(uint64(rnd.Intn(1000000)), -4))
+		b.Add(prices[i])
+		if i < 4 {
+			continue
+		}
+		window := prices[i-4:]
+		middle, upper, lower, _ := b.Value()
+		expected, _ := stats.Mean(window, 4, dfp.RoundHalfUp)
+		a.Equal(expected.String(), middle.String())
+		if lower.IsZero() {
+			continue
+		}
+		stdDev, _ := stats.StdDev(window, 4, dfp.RoundHalfUp)
+		width, _ := upper.Sub(lower)
+		diff, _ := width.Sub(stdDev.Mul(k).Mul(dfp.FromUint64(2)))
+		a.True(diff.Cmp(dfp.MustFromString("0.0004")) <= 0, "%v: %v %v", window, upper, lower)
+	}
+}
+
+func BenchmarkSMA(b *testing.B) {
+	sma, _ := NewSMA(20, 4, dfp.RoundHalfUp)
+	prices := values("1.1025", "1.1026", "1.1024", "1.1027", "1.1023")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sma.Add(prices[i%len(prices)])
+		sma.Value()
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package indicators
+
+import (
+	"time"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+// VWAP is a volume-weighted average price of the samples within a time window.
+// Samples must be added in time order.
+type VWAP struct {
+	window     time.Duration
+	samples    []Sample
+	value, qty dfp.Accumulator
+	prec       int
+	mode       dfp.RoundingMode
+}
+
+// NewVWAP returns a volume-weighted average price over a time window, which ends with the last sample.
+// If window is not positive, the average includes all the samples.
+func NewVWAP(window time.Duration, prec int, mode dfp.RoundingMode) *VWAP {
+	return &VWAP{window: window, prec: prec, mode: mode}
+}
+
+// Add adds a sample.
+func (v *VWAP) Add(s Sample) {
+	v.value.AddMul(s.Price, s.Qty)
+	v.qty.Add(s.Qty)
+	if v.window <= 0 {
+		return
+	}
+	v.samples = append(v.samples, s)
+	cutoff := s.Time.Add(-v.window)
+	for !v.samples[0].Time.After(cutoff) {
+		v.value.SubMul(v.samples[0].Price, v.samples[0].Qty)
+		v.qty.Sub(v.samples[0].Qty)
+		v.samples = v.samples[1:]
+	}
+}
+
+// Value returns the average price. It returns false, if the total quantity is zero.
+func (v *VWAP) Value() (dfp.Value, bool) {
+	if v.qty.IsZero() {
+		return 0, false
+	}
+	result, _, _ := v.value.Quo(&v.qty, v.prec, v.mode)
+	return result, true
+}
+
+// TWAP is a time-weighted average price of the samples within a time window.
+// Every price is weighted by the time until the next sample, so the last price has zero weight.
+// Samples must be added in time order. Quantities are ignored.
+type TWAP struct {
+	window  time.Duration
+	samples []Sample
+	// sum is the sum of prices multiplied by the durations in nanoseconds between all the samples.
+	sum  dfp.Accumulator
+	prec int
+	mode dfp.RoundingMode
+}
+
+// NewTWAP returns a time-weighted average price over a time window, which ends with the last sample.
+// If window is not positive, the average includes all the samples.
+func NewTWAP(window time.Duration, prec int, mode dfp.RoundingMode) *TWAP {
+	return &TWAP{window: window, prec: prec, mode: mode}
+}
+
+// Add adds a sample.
+func (t *TWAP) Add(s Sample) {
+	if len(t.samples) > 0 {
+		last := t.samples[len(t.samples)-1]
+		t.sum.AddMul(last.Price, nanoseconds(s.Time.Sub(last.Time)))
+	}
+	t.samples = append(t.samples, s)
+	if t.window <= 0 {
+		// only the first and the last samples are needed.
+		if len(t.samples) > 2 {
+			t.samples[1] = s
+			t.samples = t.samples[:2]
+		}
+		return
+	}
+	// remove the samples, if the next ones are also out of the window.
+	cutoff := s.Time.Add(-t.window)
+	for len(t.samples) > 1 && !t.samples[1].Time.After(cutoff) {
+		t.sum.SubMul(t.samples[0].Price, nanoseconds(t.samples[1].Time.Sub(t.samples[0].Time)))
+		t.samples = t.samples[1:]
+	}
+}
+
+// Value returns the average price. If all the samples have the same time, it is the last price.
+// It returns false, if no samples were added.
+func (t *TWAP) Value() (dfp.Value, bool) {
+	if len(t.samples) == 0 {
+		return 0, false
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	start := first.Time
+	var sum dfp.Accumulator
+	sum.Merge(&t.sum)
+	if cutoff := last.Time.Add(-t.window); t.window > 0 && start.Before(cutoff) {
+		// the first price is only partially within the window.
+		sum.SubMul(first.Price, nanoseconds(cutoff.Sub(start)))
+		start = cutoff
+	}
+	if !last.Time.After(start) {
+		return last.Price.RoundTo(t.prec, t.mode), true
+	}
+	var duration dfp.Accumulator
+	duration.Add(nanoseconds(last.Time.Sub(start)))
+	result, _, _ := sum.Quo(&duration, t.prec, t.mode)
+	return result, true
+}
+
+func nanoseconds(d time.Duration) dfp.Value {
+	return dfp.FromUint64(uint64(d))
+}
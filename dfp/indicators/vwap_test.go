@@ -0,0 +1,88 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+var start = time.Date(2020, 5, 8, 10, 0, 0, 0, time.UTC)
+
+func sample(sec int, price, qty string) Sample {
+	return Sample{Time: start.Add(time.Duration(sec) * time.Second), Price: dfp.MustFromString(price), Qty: dfp.MustFromString(qty)}
+}
+
+func TestVWAP(t *testing.T) {
+	a := assert.New(t)
+	v := NewVWAP(0, 2, dfp.RoundHalfUp)
+	_, ok := v.Value()
+	a.False(ok)
+	v.Add(sample(0, "10", "0"))
+	_, ok = v.Value()
+	a.False(ok)
+	v.Add(sample(1, "10", "100"))
+	v.Add(sample(2, "11", "300"))
+	result, ok := v.Value()
+	a.True(ok)
+	a.Equal("10.75", result.String())
+	v.Add(sample(3, "12.005", "1"))
+	result, _ = v.Value()
+	a.Equal("10.75", result.String())
+
+	v = NewVWAP(10*time.Second, 4, dfp.RoundDown)
+	v.Add(sample(0, "10", "100"))
+	v.Add(sample(5, "11", "300"))
+	result, _ = v.Value()
+	a.Equal("10.75", result.String())
+	// the first sample is out of the window (0, 10].
+	v.Add(sample(10, "12", "100"))
+	result, _ = v.Value()
+	a.Equal("11.25", result.String())
+	v.Add(sample(30, "13", "1"))
+	result, _ = v.Value()
+	a.Equal("13", result.String())
+	a.Len(v.samples, 1)
+}
+
+func TestTWAP(t *testing.T) {
+	a := assert.New(t)
+	tw := NewTWAP(0, 2, dfp.RoundHalfUp)
+	_, ok := tw.Value()
+	a.False(ok)
+	tw.Add(sample(0, "10", "1"))
+	result, ok := tw.Value()
+	a.True(ok)
+	a.Equal("10", result.String())
+	// 10 for 1s, 11 for 3s.
+	tw.Add(sample(1, "11", "1"))
+	tw.Add(sample(4, "12", "1"))
+	result, _ = tw.Value()
+	a.Equal("10.75", result.String())
+	tw.Add(sample(4, "13", "1"))
+	result, _ = tw.Value()
+	a.Equal("10.75", result.String())
+	a.Len(tw.samples, 2)
+
+	tw = NewTWAP(10*time.Second, 4, dfp.RoundDown)
+	tw.Add(sample(0, "10", "1"))
+	tw.Add(sample(8, "12", "1"))
+	tw.Add(sample(12, "11", "1"))
+	// the window is (2, 12]: 10 for 6s, and 12 for 4s.
+	result, _ = tw.Value()
+	a.Equal("10.8", result.String())
+	tw.Add(sample(20, "11", "1"))
+	// the window is (10, 20]: 12 for 2s, 11 for 8s.
+	result, _ = tw.Value()
+	a.Equal("11.2", result.String())
+	a.Len(tw.samples, 3)
+	tw.Add(sample(40, "15", "1"))
+	// the window is (30, 40]: 11 for 10s.
+	result, _ = tw.Value()
+	a.Equal("11", result.String())
+	a.Len(tw.samples, 2)
+}
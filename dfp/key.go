@@ -0,0 +1,89 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// KeySize is the size of an encoded key.
+	KeySize = 9
+	// KeyCoeffDigits is the number of decimal digits in a key coefficient, returned by KeyParts.
+	KeyCoeffDigits = keyCoeffDigits
+)
+
+const (
+	// keyCoeffDigits is the number of digits in a key coefficient, which is in [1e16, 1e17).
+	keyCoeffDigits = 17
+	// keyCoeffBits is the number of bits, enough to hold a 17-digit coefficient.
+	keyCoeffBits = 57
+	// keyExpBias makes key exponents positive, so that zero exponent is left for zero values.
+	keyExpBias = -minExponent + 1
+)
+
+// AppendKey appends a memcomparable key for the value to dst, and returns the extended buffer.
+// The key is KeySize bytes long, and bytes.Compare for the keys of two values agrees with Cmp,
+// so that equal values with different mantissas and exponents have equal keys.
+// The keys can be used in sorted key-value stores.
+//
+// The key is a big-endian 72-bit number, where 9 high bits hold the biased exponent
+// of the most significant digit, and 57 low bits hold a 17-digit coefficient.
+// Zero is encoded as a key of zero bytes.
+func (v Value) AppendKey(dst []byte) []byte {
+	var hi byte
+	var lo uint64
+	if exp, coeff := v.KeyParts(); coeff != 0 {
+		keyExp := uint64(int(exp) + keyExpBias)
+		hi = byte(keyExp >> (64 - keyCoeffBits))
+		lo = keyExp<<keyCoeffBits | coeff
+	}
+	var buf [KeySize]byte
+	buf[0] = hi
+	binary.BigEndian.PutUint64(buf[1:], lo)
+	return append(dst, buf[:]...)
+}
+
+// KeyParts returns the parts of the key of a non-zero value: the exponent of its most significant digit,
+// and a KeyCoeffDigits-digit coefficient, so that the value is coeff * 10^(exp-KeyCoeffDigits+1).
+// Comparing the parts of two non-zero values lexicographically agrees with Cmp.
+// For zero, it returns 0, 0.
+func (v Value) KeyParts() (exp int32, coeff uint64) {
+	m, e := split(v)
+	if m == 0 {
+		return 0, 0
+	}
+	digits := decimalDigits(m)
+	return e + expType(digits) - 1, m * pow10(keyCoeffDigits-digits)
+}
+
+// DecodeKey returns a value for a key, encoded with AppendKey.
+// Only the first KeySize bytes of the key are used.
+func DecodeKey(key []byte) (Value, error) {
+	if len(key) < KeySize {
+		return zero, fmt.Errorf("key is too short: %d bytes", len(key))
+	}
+	lo := binary.BigEndian.Uint64(key[1:])
+	keyExp := int(key[0])<<(64-keyCoeffBits) | int(lo>>keyCoeffBits)
+	coeff := lo & (1<<keyCoeffBits - 1)
+	if coeff == 0 {
+		if keyExp != 0 {
+			return zero, fmt.Errorf("invalid key")
+		}
+		return zero, nil
+	}
+	e := keyExp - keyExpBias - (keyCoeffDigits - 1)
+	for coeff%10 == 0 {
+		coeff /= 10
+		e++
+	}
+	v := adjustMantExp(coeff, expType(e)).Normalized()
+	// keys of out-of-range values and invalid coefficients do not match the keys of the results.
+	var buf [KeySize]byte
+	if !bytes.Equal(v.AppendKey(buf[:0]), key[:KeySize]) {
+		return zero, fmt.Errorf("invalid key")
+	}
+	return v, nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v   Value
+		key string
+	}{
+		{zero, "000000000000000000"},
+		{fromMantAndExp(0, 10), "000000000000000000"},
+		{Min, "00022386f26fc10000"},
+		{FromUint64(1), "01002386f26fc10000"},
+		{fromMantAndExp(10, -1), "01002386f26fc10000"},
+		{fromMantAndExp(1000, -3), "01002386f26fc10000"},
+		{MustFromString("1.5"), "0100354a6ba7a18000"},
+		{Max, "0220ffffffffffffff"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			key := test.v.AppendKey(nil)
+			a.Equal(test.key, hex.EncodeToString(key))
+			v, err := DecodeKey(key)
+			if a.NoError(err) {
+				a.True(test.v.Eq(v), "%v != %v", test.v, v)
+			}
+		})
+	}
+	a.Equal([]byte("prefix"), FromUint64(1).AppendKey([]byte("prefix"))[:6])
+
+	for _, key := range []string{
+		"00",                 // too short
+		"000000000000000001", // zero exponent
+		"010000000000000000", // zero coefficient
+		"01001fffffffffffff", // coefficient < 1e16
+		"01016345785d8a0000", // coefficient = 1e17
+		"02222386f26fc10000", // > Max
+		"ffffffffffffffffff",
+	} {
+		b, _ := hex.DecodeString(key)
+		_, err := DecodeKey(b)
+		a.Error(err, key)
+	}
+}
+
+func TestKeyParts(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v     Value
+		exp   int32
+		coeff uint64
+	}{
+		{zero, 0, 0},
+		{Min, minExponent, 10000000000000000},
+		{FromUint64(1), 0, 10000000000000000},
+		{fromMantAndExp(1000, -3), 0, 10000000000000000},
+		{MustFromString("0.015"), -2, 15000000000000000},
+		{MustFromString("12345"), 4, 12345000000000000},
+		{Max, maxExponent + 16, maxMantissa},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			exp, coeff := test.v.KeyParts()
+			a.Equal(test.exp, exp)
+			a.Equal(test.coeff, coeff)
+		})
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	values := make([]Value, 10000)
+	for i := range values {
+		m := uint64(rnd.Int63n(maxMantissa)) / pow10(rnd.Intn(digitsInMaxMantissa))
+		values[i] = fromMantAndExp(m, expType(rnd.Intn(40)-20))
+	}
+	values = append(values, zero, Min, Max)
+	for i := 1; i < len(values); i++ {
+		v1, v2 := values[i-1], values[i]
+		k1, k2 := v1.AppendKey(nil), v2.AppendKey(nil)
+		if !a.Equal(v1.Cmp(v2), bytes.Compare(k1, k2), "%v %v", v1, v2) {
+			break
+		}
+		decoded, err := DecodeKey(k1)
+		if !a.NoError(err) || !a.True(v1.Eq(decoded), "%v != %v", v1, decoded) {
+			break
+		}
+	}
+	keys := make([][]byte, len(values))
+	for i, v := range values {
+		keys[i] = v.AppendKey(nil)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+	for i, key := range keys {
+		v, err := DecodeKey(key)
+		if !a.NoError(err) || !a.True(values[i].Eq(v), "%v != %v", values[i], v) {
+			break
+		}
+	}
+}
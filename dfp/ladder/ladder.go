@@ -0,0 +1,220 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package ladder implements exchange tick tables and price ladders.
+// A tick table divides prices into bands, and every band has its own tick size, for example,
+// 0.01 below 10, and 0.05 from 10 to 50. Valid prices are the band starts plus multiples of the band tick size.
+// Every valid price has an index, which is the number of ticks between zero and the price,
+// so that stepping and counting ticks work across band boundaries.
+package ladder
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	// MaxLadderLen is the maximum number of prices, that Ladder returns.
+	MaxLadderLen = 1 << 20
+)
+
+// Band is a price band. Prices from Start up to the start of the next band have tick size Tick.
+type Band struct {
+	Start dfp.Value
+	Tick  dfp.Value
+}
+
+// TickTable is a table of price bands. It is safe for concurrent use.
+type TickTable struct {
+	bands []Band
+	// indexes contains the index of the start of every band.
+	indexes []int64
+}
+
+// NewTickTable returns a tick table for given bands.
+// The first band must start with zero, the bands must be sorted by start, and tick sizes must be positive.
+// The start of every band must be a valid price of the previous band, and a multiple of its own tick size.
+func NewTickTable(bands []Band) (*TickTable, error) {
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("no bands")
+	}
+	if !bands[0].Start.IsZero() {
+		return nil, fmt.Errorf("the first band starts with %s, not zero", bands[0].Start)
+	}
+	t := &TickTable{bands: make([]Band, len(bands)), indexes: make([]int64, len(bands))}
+	for i, b := range bands {
+		if b.Tick.IsZero() {
+			return nil, fmt.Errorf("band %d: zero tick size", i)
+		}
+		if _, ok := ticks(b.Start, 0, b.Tick); !ok {
+			return nil, fmt.Errorf("band %d: start %s is not a multiple of tick size %s", i, b.Start, b.Tick)
+		}
+		t.bands[i] = Band{Start: b.Start.Normalized(), Tick: b.Tick.Normalized()}
+		if i == 0 {
+			continue
+		}
+		prev := bands[i-1]
+		if b.Start.Cmp(prev.Start) <= 0 {
+			return nil, fmt.Errorf("band %d: start %s is not greater, than the previous one", i, b.Start)
+		}
+		n, ok := ticks(b.Start, prev.Start, prev.Tick)
+		if !ok {
+			return nil, fmt.Errorf("band %d: start %s is not a valid price of the previous band", i, b.Start)
+		}
+		if !n.IsInt64() || n.Int64() > math.MaxInt64-t.indexes[i-1] {
+			return nil, fmt.Errorf("band %d: too many ticks", i)
+		}
+		t.indexes[i] = t.indexes[i-1] + n.Int64()
+	}
+	return t, nil
+}
+
+// TickSize returns the tick size for a price.
+func (t *TickTable) TickSize(price dfp.Value) dfp.Value {
+	return t.bands[t.band(price)].Tick
+}
+
+// IsValid returns true, if the price is a valid price.
+func (t *TickTable) IsValid(price dfp.Value) bool {
+	b := t.bands[t.band(price)]
+	_, ok := ticks(price, b.Start, b.Tick)
+	return ok
+}
+
+// Round rounds the price to a valid price using given rounding mode.
+// If the rounded price has more digits, than dfp.Value can hold, it is rounded to the precision
+// of dfp.Value using the same mode, and the result may be not a valid price.
+func (t *TickTable) Round(price dfp.Value, mode dfp.RoundingMode) dfp.Value {
+	b := t.bands[t.band(price)]
+	r := new(big.Rat).Sub(price.BigRat(), b.Start.BigRat())
+	r.Quo(r, b.Tick.BigRat())
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if roundUp(rem, r.Denom(), mode) {
+		q.Add(q, big.NewInt(1))
+	}
+	r.SetInt(q).Mul(r, b.Tick.BigRat()).Add(r, b.Start.BigRat())
+	v, _, _ := dfp.FromBigRat(r, mode)
+	return v
+}
+
+// Index returns the index of a valid price, which is the number of ticks between zero and the price.
+// If the index overflows int64, dfp.ErrOverflow is returned.
+func (t *TickTable) Index(price dfp.Value) (int64, error) {
+	i := t.band(price)
+	n, ok := ticks(price, t.bands[i].Start, t.bands[i].Tick)
+	if !ok {
+		return 0, fmt.Errorf("price %s is not a valid price", price)
+	}
+	if n.Add(n, big.NewInt(t.indexes[i])); !n.IsInt64() {
+		return 0, dfp.ErrOverflow
+	}
+	return n.Int64(), nil
+}
+
+// Price returns a price for an index. The index must not be negative.
+// If the price has more digits, than dfp.Value can hold, it is rounded down, and returned along with dfp.ErrInexact.
+func (t *TickTable) Price(index int64) (dfp.Value, error) {
+	if index < 0 {
+		return 0, fmt.Errorf("negative index %d", index)
+	}
+	i := sort.Search(len(t.indexes), func(i int) bool { return t.indexes[i] > index }) - 1
+	b := t.bands[i]
+	r := new(big.Rat).SetInt64(index - t.indexes[i])
+	r.Mul(r, b.Tick.BigRat()).Add(r, b.Start.BigRat())
+	v, exact, err := dfp.FromBigRat(r, dfp.RoundDown)
+	if err == nil && !exact {
+		err = dfp.ErrInexact
+	}
+	return v, err
+}
+
+// Step returns a valid price, which is n ticks above the price, or below it, if n is negative.
+func (t *TickTable) Step(price dfp.Value, n int64) (dfp.Value, error) {
+	index, err := t.Index(price)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 && index > math.MaxInt64-n {
+		return 0, dfp.ErrOverflow
+	}
+	if index+n < 0 {
+		return 0, fmt.Errorf("price %s is less, than %d ticks", price, -n)
+	}
+	return t.Price(index + n)
+}
+
+// Ticks returns the number of ticks between two valid prices. It is negative, if to is less, than from.
+func (t *TickTable) Ticks(from, to dfp.Value) (int64, error) {
+	fromIndex, err := t.Index(from)
+	if err != nil {
+		return 0, err
+	}
+	toIndex, err := t.Index(to)
+	if err != nil {
+		return 0, err
+	}
+	return toIndex - fromIndex, nil
+}
+
+// Ladder returns all the valid prices in [from, to] in increasing order.
+// An error is returned, if there are more, than MaxLadderLen prices.
+func (t *TickTable) Ladder(from, to dfp.Value) ([]dfp.Value, error) {
+	if from.Cmp(to) > 0 {
+		return nil, nil
+	}
+	first, err := t.Index(t.Round(from, dfp.RoundUp))
+	if err != nil {
+		return nil, err
+	}
+	last, err := t.Index(t.Round(to, dfp.RoundDown))
+	if err != nil {
+		return nil, err
+	}
+	if first > last {
+		return nil, nil
+	}
+	if last-first >= MaxLadderLen {
+		return nil, fmt.Errorf("too many prices in [%s, %s]", from, to)
+	}
+	prices := make([]dfp.Value, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		price, err := t.Price(index)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// band returns the index of the band for a price.
+func (t *TickTable) band(price dfp.Value) int {
+	return sort.Search(len(t.bands), func(i int) bool { return t.bands[i].Start.Cmp(price) > 0 }) - 1
+}
+
+// ticks returns the number of ticks between start and price, and false, if the price is not on a tick.
+func ticks(price, start, tick dfp.Value) (*big.Int, bool) {
+	r := new(big.Rat).Sub(price.BigRat(), start.BigRat())
+	r.Quo(r, tick.BigRat())
+	return new(big.Int).Set(r.Num()), r.IsInt()
+}
+
+// roundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func roundUp(r, d *big.Int, mode dfp.RoundingMode) bool {
+	if r.Sign() == 0 {
+		return false
+	}
+	switch mode {
+	case dfp.RoundNearest:
+		return new(big.Int).Lsh(r, 1).Cmp(d) > 0
+	case dfp.RoundHalfUp:
+		return new(big.Int).Lsh(r, 1).Cmp(d) >= 0
+	case dfp.RoundUp:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package ladder
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func testTable() *TickTable {
+	t, err := NewTickTable([]Band{
+		{Start: dfp.MustFromString("0"), Tick: dfp.MustFromString("0.01")},
+		{Start: dfp.MustFromString("10"), Tick: dfp.MustFromString("0.05")},
+		{Start: dfp.MustFromString("50"), Tick: dfp.MustFromString("0.1")},
+		{Start: dfp.MustFromString("100"), Tick: dfp.MustFromString("1")},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func valuesToStrings(values []dfp.Value) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.String()
+	}
+	return strs
+}
+
+func TestNewTickTable(t *testing.T) {
+	a := assert.New(t)
+	band := func(start, tick string) Band {
+		return Band{Start: dfp.MustFromString(start), Tick: dfp.MustFromString(tick)}
+	}
+	tests := []struct {
+		bands []Band
+		err   bool
+	}{
+		{nil, true},
+		{[]Band{band("0", "0.01")}, false},
+		{[]Band{band("1", "0.01")}, true},
+		{[]Band{band("0", "0")}, true},
+		{[]Band{band("0", "0.01"), band("10", "0.05")}, false},
+		{[]Band{band("0", "0.01"), band("10", "0.05"), band("10", "0.1")}, true},
+		{[]Band{band("0", "0.01"), band("10", "0.05"), band("5", "0.1")}, true},
+		{[]Band{band("0", "0.01"), band("10.005", "0.005")}, true},
+		{[]Band{band("0", "0.05"), band("10.02", "0.01")}, true},
+		{[]Band{band("0", "0.01"), band("10.01", "0.05")}, true},
+		{[]Band{band("0", "0.3"), band("0.9", "0.2")}, true},
+		{[]Band{band("0", "0.3"), band("1.2", "0.4")}, false},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			table, err := NewTickTable(test.bands)
+			if test.err {
+				a.Error(err)
+				a.Nil(table)
+			} else {
+				a.NoError(err)
+				a.NotNil(table)
+			}
+		})
+	}
+}
+
+func TestTickTableRound(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	tests := []struct {
+		price    string
+		tick     string
+		down     string
+		nearest  string
+		up       string
+		halfUp   string
+		hasError bool
+	}{
+		{"0", "0.01", "0", "0", "0", "0", false},
+		{"0.001", "0.01", "0", "0", "0.01", "0", true},
+		{"1.235", "0.01", "1.23", "1.23", "1.24", "1.24", true},
+		{"9.99", "0.01", "9.99", "9.99", "9.99", "9.99", false},
+		{"9.995", "0.01", "9.99", "9.99", "10", "10", true},
+		{"10", "0.05", "10", "10", "10", "10", false},
+		{"10.025", "0.05", "10", "10", "10.05", "10.05", true},
+		{"10.03", "0.05", "10", "10.05", "10.05", "10.05", true},
+		{"49.97", "0.05", "49.95", "49.95", "50", "49.95", true},
+		{"75.55", "0.1", "75.5", "75.5", "75.6", "75.6", true},
+		{"1234.5", "1", "1234", "1234", "1235", "1235", true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			price := dfp.MustFromString(test.price)
+			a.Equal(test.tick, table.TickSize(price).String())
+			a.Equal(test.down, table.Round(price, dfp.RoundDown).String())
+			a.Equal(test.nearest, table.Round(price, dfp.RoundNearest).String())
+			a.Equal(test.up, table.Round(price, dfp.RoundUp).String())
+			a.Equal(test.halfUp, table.Round(price, dfp.RoundHalfUp).String())
+			a.Equal(!test.hasError, table.IsValid(price))
+		})
+	}
+}
+
+func TestTickTableIndex(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	tests := []struct {
+		price string
+		index int64
+		err   bool
+	}{
+		{"0", 0, false},
+		{"0.01", 1, false},
+		{"9.99", 999, false},
+		{"10", 1000, false},
+		{"10.05", 1001, false},
+		{"50", 1800, false},
+		{"99.9", 2299, false},
+		{"100", 2300, false},
+		{"1e6", 2300 + 999900, false},
+		{"10.01", 0, true},
+		{"100.5", 0, true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			index, err := table.Index(dfp.MustFromString(test.price))
+			if test.err {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			a.Equal(test.index, index)
+			price, err := table.Price(index)
+			a.NoError(err)
+			a.True(dfp.MustFromString(test.price).Eq(price), "%s != %s", test.price, price)
+		})
+	}
+	_, err := table.Price(-1)
+	a.Error(err)
+}
+
+func TestTickTableStep(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	tests := []struct {
+		price    string
+		n        int64
+		expected string
+		err      bool
+	}{
+		{"1.5", 0, "1.5", false},
+		{"1.5", 3, "1.53", false},
+		{"1.5", -3, "1.47", false},
+		{"9.99", 1, "10", false},
+		{"9.98", 3, "10.05", false},
+		{"10.05", -2, "9.99", false},
+		{"49.95", 2, "50.1", false},
+		{"50.1", -2, "49.95", false},
+		{"99.9", 2, "101", false},
+		{"101", -3, "99.8", false},
+		{"0.02", -2, "0", false},
+		{"0.02", -3, "", true},
+		{"10.01", 1, "", true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			price, err := table.Step(dfp.MustFromString(test.price), test.n)
+			if test.err {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			a.Equal(test.expected, price.String())
+		})
+	}
+}
+
+func TestTickTableTicks(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	tests := []struct {
+		from, to string
+		expected int64
+		err      bool
+	}{
+		{"1", "1", 0, false},
+		{"1", "2", 100, false},
+		{"2", "1", -100, false},
+		{"9.9", "10.5", 20, false},
+		{"10.5", "9.9", -20, false},
+		{"0", "100", 2300, false},
+		{"49.9", "51", 12, false},
+		{"1.001", "2", 0, true},
+		{"1", "10.01", 0, true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			n, err := table.Ticks(dfp.MustFromString(test.from), dfp.MustFromString(test.to))
+			if test.err {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			a.Equal(test.expected, n)
+		})
+	}
+}
+
+func TestTickTableLadder(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	tests := []struct {
+		from, to string
+		expected []string
+	}{
+		{"1", "1", []string{"1"}},
+		{"1", "0.99", nil},
+		{"1.001", "1.009", nil},
+		{"1.001", "1.03", []string{"1.01", "1.02", "1.03"}},
+		{"9.97", "10.12", []string{"9.97", "9.98", "9.99", "10", "10.05", "10.1"}},
+		{"49.86", "50.25", []string{"49.9", "49.95", "50", "50.1", "50.2"}},
+		{"99.75", "102.5", []string{"99.8", "99.9", "100", "101", "102"}},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			prices, err := table.Ladder(dfp.MustFromString(test.from), dfp.MustFromString(test.to))
+			a.NoError(err)
+			if test.expected == nil {
+				a.Empty(prices)
+				return
+			}
+			a.Equal(test.expected, valuesToStrings(prices))
+		})
+	}
+	_, err := table.Ladder(dfp.MustFromString("0"), dfp.MustFromString("1e7"))
+	a.Error(err)
+	_, err = table.Ladder(dfp.MustFromString("1"), dfp.MustFromString("1e30"))
+	a.Equal(dfp.ErrOverflow, err)
+}
+
+func TestTickTableLargePrices(t *testing.T) {
+	a := assert.New(t)
+	table := testTable()
+	price := dfp.MustFromString("1e30")
+	a.Equal("1000000000000000000000000000000", table.Round(price, dfp.RoundDown).String())
+	a.True(table.IsValid(price))
+	_, err := table.Index(price)
+	a.Equal(dfp.ErrOverflow, err)
+	_, err = table.Step(price, 1)
+	a.Equal(dfp.ErrOverflow, err)
+	_, err = table.Ticks(dfp.MustFromString("1"), price)
+	a.Equal(dfp.ErrOverflow, err)
+
+	_, err = table.Step(dfp.MustFromString("1"), math.MaxInt64)
+	a.Equal(dfp.ErrOverflow, err)
+	_, err = table.Step(dfp.MustFromString("1"), math.MinInt64)
+	a.Error(err)
+
+	// 1e18 - 2199 has 18 digits.
+	v, err := table.Price(1e18 + 1)
+	a.Equal(dfp.ErrInexact, err)
+	a.Equal("999999999999997800", v.String())
+}
@@ -0,0 +1,238 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package ordered implements an ordered map keyed by decimal floating-point values, like price levels of an order book.
+// Keys are normalized, so that 1.5 and 1.50 are the same key. The map is a skip list, which supports
+// lookups, insertions and deletions in logarithmic time, Min and Max in constant time, and iteration in both directions.
+package ordered
+
+import (
+	"encoding/binary"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	// maxLevel is the maximum number of levels, which is enough for 4^maxLevel items.
+	maxLevel = 24
+)
+
+// Map is an ordered map from values to arbitrary items. The zero value is an empty map ready to use.
+// A map is not safe for concurrent use.
+type Map struct {
+	head  [maxLevel]*node
+	last  *node
+	level int
+	len   int
+	rnd   uint64
+}
+
+type node struct {
+	key  key
+	v    dfp.Value
+	item interface{}
+	prev *node
+	next []*node
+}
+
+// key is an order-preserving representation of a value, made of the bytes of dfp.Value.AppendKey:
+// hi is the first byte, and lo holds the rest. Equal values have equal keys.
+type key struct {
+	hi uint8
+	lo uint64
+}
+
+func makeKey(v dfp.Value) key {
+	var buf [dfp.KeySize]byte
+	b := v.AppendKey(buf[:0])
+	return key{hi: b[0], lo: binary.BigEndian.Uint64(b[1:])}
+}
+
+func (k key) less(other key) bool {
+	return k.hi < other.hi || k.hi == other.hi && k.lo < other.lo
+}
+
+// New returns an empty map.
+func New() *Map {
+	return &Map{}
+}
+
+// Len returns the number of items.
+func (m *Map) Len() int {
+	return m.len
+}
+
+// Get returns the item for a key, and true, if it exists.
+func (m *Map) Get(k dfp.Value) (interface{}, bool) {
+	if n := m.find(makeKey(k)); n != nil {
+		return n.item, true
+	}
+	return nil, false
+}
+
+// Set sets the item for a key.
+func (m *Map) Set(k dfp.Value, item interface{}) {
+	var update [maxLevel]*node
+	mk := makeKey(k)
+	pred := m.findLess(mk, &update)
+	if n := m.next(pred, 0); n != nil && n.key == mk {
+		n.item = item
+		return
+	}
+	// the predecessors at new levels are the head.
+	level := m.randomLevel()
+	if level > m.level {
+		m.level = level
+	}
+	n := &node{key: mk, v: k.Normalized(), item: item, prev: pred, next: make([]*node, level)}
+	for i := 0; i < level; i++ {
+		n.next[i] = m.next(update[i], i)
+		m.setNext(update[i], i, n)
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n
+	} else {
+		m.last = n
+	}
+	m.len++
+}
+
+// Delete deletes a key, and returns true, if it existed.
+func (m *Map) Delete(k dfp.Value) bool {
+	var update [maxLevel]*node
+	mk := makeKey(k)
+	pred := m.findLess(mk, &update)
+	n := m.next(pred, 0)
+	if n == nil || n.key != mk {
+		return false
+	}
+	for i := range n.next {
+		m.setNext(update[i], i, n.next[i])
+	}
+	if n.next[0] != nil {
+		n.next[0].prev = n.prev
+	} else {
+		m.last = n.prev
+	}
+	for m.level > 0 && m.head[m.level-1] == nil {
+		m.level--
+	}
+	m.len--
+	return true
+}
+
+// Min returns the smallest key and its item. It returns false, if the map is empty.
+func (m *Map) Min() (dfp.Value, interface{}, bool) {
+	return result(m.head[0])
+}
+
+// Max returns the greatest key and its item. It returns false, if the map is empty.
+func (m *Map) Max() (dfp.Value, interface{}, bool) {
+	return result(m.last)
+}
+
+// Floor returns the greatest key less than or equal to k, and its item. It returns false, if there is no such key.
+func (m *Map) Floor(k dfp.Value) (dfp.Value, interface{}, bool) {
+	return result(m.floor(makeKey(k)))
+}
+
+// Ceiling returns the smallest key greater than or equal to k, and its item.
+// It returns false, if there is no such key.
+func (m *Map) Ceiling(k dfp.Value) (dfp.Value, interface{}, bool) {
+	return result(m.next(m.findLess(makeKey(k), nil), 0))
+}
+
+// Ascend calls f for all the keys in increasing order, until f returns false.
+func (m *Map) Ascend(f func(k dfp.Value, item interface{}) bool) {
+	for n := m.head[0]; n != nil && f(n.v, n.item); n = n.next[0] {
+	}
+}
+
+// Descend calls f for all the keys in decreasing order, until f returns false.
+func (m *Map) Descend(f func(k dfp.Value, item interface{}) bool) {
+	for n := m.last; n != nil && f(n.v, n.item); n = n.prev {
+	}
+}
+
+// Range calls f for the keys in [from, to] in increasing order, until f returns false.
+func (m *Map) Range(from, to dfp.Value, f func(k dfp.Value, item interface{}) bool) {
+	toKey := makeKey(to)
+	for n := m.next(m.findLess(makeKey(from), nil), 0); n != nil && !toKey.less(n.key) && f(n.v, n.item); n = n.next[0] {
+	}
+}
+
+// RangeDesc calls f for the keys in [from, to] in decreasing order, until f returns false.
+func (m *Map) RangeDesc(from, to dfp.Value, f func(k dfp.Value, item interface{}) bool) {
+	fromKey := makeKey(from)
+	for n := m.floor(makeKey(to)); n != nil && !n.key.less(fromKey) && f(n.v, n.item); n = n.prev {
+	}
+}
+
+// findLess returns the last node with a key less than k, or nil, if there is no such node.
+// If update is not nil, it is filled with such nodes for every level.
+func (m *Map) findLess(k key, update *[maxLevel]*node) *node {
+	var pred *node
+	for i := m.level - 1; i >= 0; i-- {
+		for n := m.next(pred, i); n != nil && n.key.less(k); n = m.next(pred, i) {
+			pred = n
+		}
+		if update != nil {
+			update[i] = pred
+		}
+	}
+	return pred
+}
+
+func (m *Map) find(k key) *node {
+	if n := m.next(m.findLess(k, nil), 0); n != nil && n.key == k {
+		return n
+	}
+	return nil
+}
+
+func (m *Map) floor(k key) *node {
+	pred := m.findLess(k, nil)
+	if n := m.next(pred, 0); n != nil && n.key == k {
+		return n
+	}
+	return pred
+}
+
+// next returns the next node of n at given level, where nil n is the head.
+func (m *Map) next(n *node, level int) *node {
+	if n == nil {
+		return m.head[level]
+	}
+	return n.next[level]
+}
+
+func (m *Map) setNext(n *node, level int, next *node) {
+	if n == nil {
+		m.head[level] = next
+	} else {
+		n.next[level] = next
+	}
+}
+
+// randomLevel returns a random level, which is at least i with probability 1/4^(i-1).
+func (m *Map) randomLevel() int {
+	if m.rnd == 0 {
+		m.rnd = 0x9e3779b97f4a7c15
+	}
+	// xorshift64.
+	m.rnd ^= m.rnd << 13
+	m.rnd ^= m.rnd >> 7
+	m.rnd ^= m.rnd << 17
+	level, r := 1, m.rnd
+	for level < maxLevel && r&3 == 0 {
+		level++
+		r >>= 2
+	}
+	return level
+}
+
+func result(n *node) (dfp.Value, interface{}, bool) {
+	if n == nil {
+		return 0, nil, false
+	}
+	return n.v, n.item, true
+}
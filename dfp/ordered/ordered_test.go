@@ -0,0 +1,170 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package ordered
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func keys(iterate func(f func(k dfp.Value, item interface{}) bool)) []string {
+	var result []string
+	iterate(func(k dfp.Value, item interface{}) bool {
+		result = append(result, k.String())
+		return true
+	})
+	return result
+}
+
+func TestMap(t *testing.T) {
+	a := assert.New(t)
+	var m Map
+	_, _, ok := m.Min()
+	a.False(ok)
+	_, _, ok = m.Max()
+	a.False(ok)
+	_, _, ok = m.Floor(dfp.FromUint64(1))
+	a.False(ok)
+	a.False(m.Delete(dfp.FromUint64(1)))
+
+	m.Set(dfp.FromMantAndExp(150, -2), "a")
+	m.Set(dfp.FromMantAndExp(15, -1), "b")
+	a.Equal(1, m.Len())
+	item, ok := m.Get(dfp.MustFromString("1.500"))
+	a.True(ok)
+	a.Equal("b", item)
+	k, _, _ := m.Min()
+	a.Equal(dfp.FromMantAndExp(15, -1), k)
+
+	for _, s := range []string{"1.25", "0", "100", "1.3", "99.99", "0.001"} {
+		m.Set(dfp.MustFromString(s), s)
+	}
+	a.Equal(7, m.Len())
+	a.Equal([]string{"0", "0.001", "1.25", "1.3", "1.5", "99.99", "100"}, keys(m.Ascend))
+	a.Equal([]string{"100", "99.99", "1.5", "1.3", "1.25", "0.001", "0"}, keys(m.Descend))
+	k, item, _ = m.Max()
+	a.Equal("100", k.String())
+	a.Equal("100", item)
+
+	tests := []struct {
+		k              string
+		floor, ceiling string
+	}{
+		{"0", "0", "0"},
+		{"0.0001", "0", "0.001"},
+		{"1.27", "1.25", "1.3"},
+		{"1.30", "1.3", "1.3"},
+		{"1e3", "100", ""},
+	}
+	for _, test := range tests {
+		k, _, ok := m.Floor(dfp.MustFromString(test.k))
+		if a.Equal(test.floor != "", ok, test.k) && ok {
+			a.Equal(test.floor, k.String(), test.k)
+		}
+		k, _, ok = m.Ceiling(dfp.MustFromString(test.k))
+		if a.Equal(test.ceiling != "", ok, test.k) && ok {
+			a.Equal(test.ceiling, k.String(), test.k)
+		}
+	}
+
+	from, to := dfp.MustFromString("1.26"), dfp.MustFromString("99.99")
+	a.Equal([]string{"1.3", "1.5", "99.99"}, keys(func(f func(k dfp.Value, item interface{}) bool) { m.Range(from, to, f) }))
+	a.Equal([]string{"99.99", "1.5", "1.3"}, keys(func(f func(k dfp.Value, item interface{}) bool) { m.RangeDesc(from, to, f) }))
+	a.Empty(keys(func(f func(k dfp.Value, item interface{}) bool) { m.Range(to, from, f) }))
+	var first []string
+	m.Ascend(func(k dfp.Value, item interface{}) bool {
+		first = append(first, k.String())
+		return len(first) < 2
+	})
+	a.Equal([]string{"0", "0.001"}, first)
+
+	a.True(m.Delete(dfp.MustFromString("100.0")))
+	a.True(m.Delete(dfp.MustFromString("0")))
+	a.False(m.Delete(dfp.MustFromString("0")))
+	a.Equal(5, m.Len())
+	a.Equal([]string{"99.99", "1.5", "1.3", "1.25", "0.001"}, keys(m.Descend))
+	_, ok = m.Get(dfp.MustFromString("100"))
+	a.False(ok)
+}
+
+func TestMapRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	m := New()
+	reference := make(map[dfp.Value]int)
+	for i := 0; i < 20000; i++ {
+		v := dfp.FromMantAndExp(uint64(rnd.Intn(1000)), -int32(rnd.Intn(3)))
+		if rnd.Intn(3) == 0 {
+			_, exists := reference[v.Normalized()]
+			a.Equal(exists, m.Delete(v))
+			delete(reference, v.Normalized())
+		} else {
+			m.Set(v, i)
+			reference[v.Normalized()] = i
+		}
+	}
+	a.Equal(len(reference), m.Len())
+	sorted := make([]dfp.Value, 0, len(reference))
+	for v := range reference {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	i := 0
+	m.Ascend(func(k dfp.Value, item interface{}) bool {
+		a.True(sorted[i].Eq(k))
+		a.Equal(reference[sorted[i]], item)
+		i++
+		return true
+	})
+	a.Equal(len(sorted), i)
+	m.Descend(func(k dfp.Value, item interface{}) bool {
+		i--
+		a.True(sorted[i].Eq(k))
+		return true
+	})
+	for j := 0; j < 1000; j++ {
+		v := dfp.FromMantAndExp(uint64(rnd.Intn(10000)), -int32(rnd.Intn(4)))
+		idx := sort.Search(len(sorted), func(i int) bool { return sorted[i].Cmp(v) >= 0 })
+		k, _, ok := m.Ceiling(v)
+		if a.Equal(idx < len(sorted), ok) && ok {
+			a.True(sorted[idx].Eq(k))
+		}
+		if idx == len(sorted) || !sorted[idx].Eq(v) {
+			idx--
+		}
+		k, _, ok = m.Floor(v)
+		if a.Equal(idx >= 0, ok) && ok {
+			a.True(sorted[idx].Eq(k))
+		}
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]dfp.Value, 10000)
+	for i := range values {
+		values[i] = dfp.FromMantAndExp(uint64(rnd.Intn(100000)), -2)
+	}
+	m := New()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Set(values[i%len(values)], i)
+	}
+}
+
+func BenchmarkFloor(b *testing.B) {
+	m := New()
+	for i := 0; i < 10000; i++ {
+		m.Set(dfp.FromMantAndExp(uint64(i*10), -2), i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Floor(dfp.FromMantAndExp(uint64(i%100000), -2))
+	}
+}
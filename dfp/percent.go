@@ -0,0 +1,129 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	percentSuffix = "%"
+	bpSuffix      = "bp"
+	bpsSuffix     = "bps"
+)
+
+// Percent is a value expressed in percents, so that Percent 1.5 means 0.015.
+type Percent Value
+
+// BasisPoints is a value expressed in basis points (hundredths of a percent), so that BasisPoints 25 means 0.0025.
+type BasisPoints Value
+
+// ParsePercent parses a string like "1.5%" into a percent value. The '%' sign is required.
+func ParsePercent(s string) (Percent, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasSuffix(s, percentSuffix) {
+		return Percent(zero), fmt.Errorf("missing %q suffix", percentSuffix)
+	}
+	v, err := FromString(strings.TrimSuffix(s, percentSuffix))
+	return Percent(v), err
+}
+
+// MustParsePercent parses a string into a percent value. It panics on an error.
+func MustParsePercent(s string) Percent {
+	p, err := ParsePercent(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Value returns the number of percents, so that for 1.5% it is 1.5.
+func (p Percent) Value() Value {
+	return Value(p)
+}
+
+// Fraction returns the percent as a fraction, so that for 1.5% it is 0.015.
+func (p Percent) Fraction() Value {
+	return shiftExp(Value(p), -2)
+}
+
+// BasisPoints converts the percent to basis points, so that 1.5% is 150bp.
+func (p Percent) BasisPoints() BasisPoints {
+	return BasisPoints(shiftExp(Value(p), 2))
+}
+
+// Of returns p percent of amount.
+func (p Percent) Of(amount Value) Value {
+	return shiftExp(amount.Mul(Value(p)), -2)
+}
+
+// Apply returns amount increased by p percent, that is amount * (1 + p/100).
+func (p Percent) Apply(amount Value) Value {
+	return amount.Add(p.Of(amount))
+}
+
+// String returns a string representation of the percent, like "1.5%".
+func (p Percent) String() string {
+	return Value(p).String() + percentSuffix
+}
+
+// ParseBasisPoints parses a string like "25bp" or "25bps" into a basis points value. The suffix is required.
+func ParseBasisPoints(s string) (BasisPoints, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasSuffix(s, bpsSuffix):
+		s = strings.TrimSuffix(s, bpsSuffix)
+	case strings.HasSuffix(s, bpSuffix):
+		s = strings.TrimSuffix(s, bpSuffix)
+	default:
+		return BasisPoints(zero), fmt.Errorf("missing %q suffix", bpSuffix)
+	}
+	v, err := FromString(s)
+	return BasisPoints(v), err
+}
+
+// MustParseBasisPoints parses a string into a basis points value. It panics on an error.
+func MustParseBasisPoints(s string) BasisPoints {
+	bp, err := ParseBasisPoints(s)
+	if err != nil {
+		panic(err)
+	}
+	return bp
+}
+
+// Value returns the number of basis points, so that for 25bp it is 25.
+func (bp BasisPoints) Value() Value {
+	return Value(bp)
+}
+
+// Fraction returns basis points as a fraction, so that for 25bp it is 0.0025.
+func (bp BasisPoints) Fraction() Value {
+	return shiftExp(Value(bp), -4)
+}
+
+// Percent converts basis points to a percent, so that 25bp is 0.25%.
+func (bp BasisPoints) Percent() Percent {
+	return Percent(shiftExp(Value(bp), -2))
+}
+
+// Of returns bp basis points of amount.
+func (bp BasisPoints) Of(amount Value) Value {
+	return shiftExp(amount.Mul(Value(bp)), -4)
+}
+
+// Apply returns amount increased by bp basis points, that is amount * (1 + bp/10000).
+func (bp BasisPoints) Apply(amount Value) Value {
+	return amount.Add(bp.Of(amount))
+}
+
+// String returns a string representation of basis points, like "25bp".
+func (bp BasisPoints) String() string {
+	return Value(bp).String() + bpSuffix
+}
+
+// shiftExp multiplies v by 10^shift without touching the mantissa.
+func shiftExp(v Value, shift int) Value {
+	m, e := split(v)
+	return adjustMantExp(m, e+expType(shift))
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePercent(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		err         string
+	}{
+		{"1.5%", "1.5%", ""},
+		{" 150% ", "150%", ""},
+		{"0.25%", "0.25%", ""},
+		{"0%", "0%", ""},
+		{"1.5", "", `missing "%" suffix`},
+		{"-1.5%", "", "negative value"},
+		{"abc%", "", "parsing failed: unexpected symbol 'a' at pos 1"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			p, err := ParsePercent(test.s)
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, p.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseBasisPoints(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		s, expected string
+		err         string
+	}{
+		{"25bp", "25bp", ""},
+		{"25bps", "25bp", ""},
+		{" 0.5bp", "0.5bp", ""},
+		{"25", "", `missing "bp" suffix`},
+		{"25%", "", `missing "bp" suffix`},
+		{"-25bp", "", "negative value"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			bp, err := ParseBasisPoints(test.s)
+			if len(test.err) == 0 {
+				if a.NoError(err) {
+					a.Equal(test.expected, bp.String())
+				}
+			} else {
+				a.EqualError(err, test.err)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	a := assert.New(t)
+	p := MustParsePercent("1.5%")
+	a.Equal("1.5", p.Value().String())
+	a.Equal("0.015", p.Fraction().String())
+	a.Equal("150bp", p.BasisPoints().String())
+	a.Equal("1.5%", p.BasisPoints().Percent().String())
+	a.Equal("3.75", p.Of(MustFromString("250")).String())
+	a.Equal("253.75", p.Apply(MustFromString("250")).String())
+	a.Equal("0", p.Of(zero).String())
+
+	bp := MustParseBasisPoints("25bp")
+	a.Equal("25", bp.Value().String())
+	a.Equal("0.0025", bp.Fraction().String())
+	a.Equal("0.25%", bp.Percent().String())
+	a.Equal("25bp", bp.Percent().BasisPoints().String())
+	a.Equal("2.5", bp.Of(MustFromString("1000")).String())
+	a.Equal("1002.5", bp.Apply(MustFromString("1000")).String())
+
+	a.Panics(func() { MustParsePercent("1.5") })
+	a.Panics(func() { MustParseBasisPoints("1.5") })
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package sbe implements conversions between dfp.Value and Simple Binary Encoding price composites,
+// like CME MDP3 PRICE9 and PRICENULL9 with a constant exponent, and decimal composites with a variable one.
+// Null values are reported explicitly, and never turn into zero prices.
+// Negative prices can not be represented with dfp.Value, so they are decoded with an error.
+package sbe
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	// Price9Exponent is the constant exponent of PRICE9 and PRICENULL9 composites.
+	Price9Exponent = -9
+	// NullPrice9 is the null mantissa of a PRICENULL9 composite.
+	NullPrice9 = math.MaxInt64
+
+	// NullInt64 is the SBE null value of an optional int64 mantissa.
+	NullInt64 = math.MinInt64
+	// NullInt32 is the SBE null value of an optional int32 mantissa.
+	NullInt32 = math.MinInt32
+
+	// Decimal64Size is the encoded size of a Decimal64 composite.
+	Decimal64Size = 9
+	// Decimal32Size is the encoded size of a Decimal32 composite.
+	Decimal32Size = 5
+)
+
+// DecodePrice returns a value for a mantissa with a constant exponent.
+// If the value can not be represented precisely, the truncated value is returned along with dfp.ErrInexact.
+func DecodePrice(mant int64, exp int) (dfp.Value, error) {
+	return dfp.FromScaledInt64(mant, -exp)
+}
+
+// EncodePrice returns a mantissa for a constant exponent.
+// If the value has more decimal places, it is truncated, and dfp.ErrInexact is returned.
+func EncodePrice(v dfp.Value, exp int) (int64, error) {
+	return v.ToScaledInt64(-exp, dfp.RoundDown)
+}
+
+// DecodePrice9 returns a value for a PRICE9 composite.
+func DecodePrice9(mant int64) (dfp.Value, error) {
+	return DecodePrice(mant, Price9Exponent)
+}
+
+// EncodePrice9 returns a PRICE9 composite mantissa.
+func EncodePrice9(v dfp.Value) (int64, error) {
+	return EncodePrice(v, Price9Exponent)
+}
+
+// DecodePriceNull9 returns a value for a PRICENULL9 composite.
+// Returns false, if the mantissa is null.
+func DecodePriceNull9(mant int64) (v dfp.Value, valid bool, err error) {
+	if mant == NullPrice9 {
+		return v, false, nil
+	}
+	v, err = DecodePrice9(mant)
+	return v, true, err
+}
+
+// EncodePriceNull9 returns a PRICENULL9 composite mantissa.
+// If valid is false, the null mantissa is returned.
+func EncodePriceNull9(v dfp.Value, valid bool) (int64, error) {
+	if !valid {
+		return NullPrice9, nil
+	}
+	return EncodePrice9(v)
+}
+
+// Decimal64 is a decimal composite with an int64 mantissa and an int8 exponent.
+// A null composite has a NullInt64 mantissa.
+type Decimal64 struct {
+	Mantissa int64
+	Exponent int8
+}
+
+// NullDecimal64 is a null Decimal64 composite.
+var NullDecimal64 = Decimal64{Mantissa: NullInt64}
+
+// NewDecimal64 returns a composite for given value. Any value can be represented precisely.
+func NewDecimal64(v dfp.Value) (Decimal64, error) {
+	m, e, err := mantExp(v, math.MaxInt64)
+	return Decimal64{Mantissa: int64(m), Exponent: int8(e)}, err
+}
+
+// ReadDecimal64 reads a little-endian composite from b.
+// It panics, if len(b) < Decimal64Size.
+func ReadDecimal64(b []byte) Decimal64 {
+	return Decimal64{Mantissa: int64(binary.LittleEndian.Uint64(b)), Exponent: int8(b[8])}
+}
+
+// Put writes the composite into b in little-endian byte order.
+// It panics, if len(b) < Decimal64Size.
+func (d Decimal64) Put(b []byte) {
+	binary.LittleEndian.PutUint64(b, uint64(d.Mantissa))
+	b[8] = byte(d.Exponent)
+}
+
+// IsNull returns true, if the composite is null.
+func (d Decimal64) IsNull() bool {
+	return d.Mantissa == NullInt64
+}
+
+// Value returns the value of the composite.
+// Returns false, if the composite is null.
+// If the value can not be represented precisely, the truncated value is returned along with dfp.ErrInexact.
+func (d Decimal64) Value() (v dfp.Value, valid bool, err error) {
+	if d.IsNull() {
+		return v, false, nil
+	}
+	v, err = DecodePrice(d.Mantissa, int(d.Exponent))
+	return v, true, err
+}
+
+// Decimal32 is a decimal composite with an int32 mantissa and an int8 exponent.
+// A null composite has a NullInt32 mantissa.
+type Decimal32 struct {
+	Mantissa int32
+	Exponent int8
+}
+
+// NullDecimal32 is a null Decimal32 composite.
+var NullDecimal32 = Decimal32{Mantissa: NullInt32}
+
+// NewDecimal32 returns a composite for given value.
+// If the mantissa does not fit int32, it is truncated, and dfp.ErrInexact is returned.
+// Returns dfp.ErrOverflow, if the value's exponent does not fit int8.
+func NewDecimal32(v dfp.Value) (Decimal32, error) {
+	m, e, err := mantExp(v, math.MaxInt32)
+	return Decimal32{Mantissa: int32(m), Exponent: int8(e)}, err
+}
+
+// ReadDecimal32 reads a little-endian composite from b.
+// It panics, if len(b) < Decimal32Size.
+func ReadDecimal32(b []byte) Decimal32 {
+	return Decimal32{Mantissa: int32(binary.LittleEndian.Uint32(b)), Exponent: int8(b[4])}
+}
+
+// Put writes the composite into b in little-endian byte order.
+// It panics, if len(b) < Decimal32Size.
+func (d Decimal32) Put(b []byte) {
+	binary.LittleEndian.PutUint32(b, uint32(d.Mantissa))
+	b[4] = byte(d.Exponent)
+}
+
+// IsNull returns true, if the composite is null.
+func (d Decimal32) IsNull() bool {
+	return d.Mantissa == NullInt32
+}
+
+// Value returns the value of the composite.
+// Returns false, if the composite is null.
+func (d Decimal32) Value() (v dfp.Value, valid bool, err error) {
+	if d.IsNull() {
+		return v, false, nil
+	}
+	v, err = DecodePrice(int64(d.Mantissa), int(d.Exponent))
+	return v, true, err
+}
+
+// mantExp returns v's mantissa, that does not exceed maxMant, and an exponent, that fits int8.
+// Exponents of dfp.Value are never less, than math.MinInt8.
+func mantExp(v dfp.Value, maxMant uint64) (m uint64, e int, err error) {
+	v = v.Normalized()
+	m, e = v.MantUint64(), int(v.Exp())
+	if m == 0 {
+		return 0, 0, nil
+	}
+	for m > maxMant {
+		if m%10 != 0 {
+			err = dfp.ErrInexact
+		}
+		m /= 10
+		e++
+	}
+	for e > math.MaxInt8 && m*10 <= maxMant {
+		m *= 10
+		e--
+	}
+	if e > math.MaxInt8 {
+		return 0, 0, dfp.ErrOverflow
+	}
+	return m, e, err
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package sbe
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func TestPrice9(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		mant     int64
+		expected string
+		err      string
+	}{
+		{0, "0", ""},
+		{1, "0.000000001", ""},
+		{4512250000000, "4512.25", ""},
+		{9223372036854775000, "9223372036.854775", ""},
+		{9223372036854775807, "9223372036.854775", "inexact result"},
+		{-1, "", "negative value"},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := DecodePrice9(test.mant)
+			if len(test.err) > 0 {
+				a.EqualError(err, test.err)
+				if err != dfp.ErrInexact {
+					return
+				}
+			} else if !a.NoError(err) {
+				return
+			}
+			a.Equal(test.expected, v.String())
+			if err == nil {
+				mant, err := EncodePrice9(v)
+				a.NoError(err)
+				a.Equal(test.mant, mant)
+			}
+		})
+	}
+	_, err := EncodePrice9(dfp.MustFromString("1.0000000001"))
+	a.Equal(dfp.ErrInexact, err)
+	_, err = EncodePrice9(dfp.MustFromString("1e10"))
+	a.Equal(dfp.ErrOverflow, err)
+
+	mant, err := EncodePrice(dfp.MustFromString("4512.25"), -2)
+	a.NoError(err)
+	a.Equal(int64(451225), mant)
+	v, err := DecodePrice(451225, -2)
+	a.NoError(err)
+	a.Equal("4512.25", v.String())
+}
+
+func TestPriceNull9(t *testing.T) {
+	a := assert.New(t)
+	v, valid, err := DecodePriceNull9(NullPrice9)
+	a.NoError(err)
+	a.False(valid)
+	a.True(v.IsZero())
+
+	v, valid, err = DecodePriceNull9(0)
+	a.NoError(err)
+	a.True(valid)
+	a.True(v.IsZero())
+
+	v, valid, err = DecodePriceNull9(1500000000)
+	a.NoError(err)
+	a.True(valid)
+	a.Equal("1.5", v.String())
+
+	mant, err := EncodePriceNull9(v, true)
+	a.NoError(err)
+	a.Equal(int64(1500000000), mant)
+	mant, err = EncodePriceNull9(v, false)
+	a.NoError(err)
+	a.Equal(int64(NullPrice9), mant)
+}
+
+func TestDecimal64(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v        string
+		expected Decimal64
+		err      error
+	}{
+		{"0", Decimal64{}, nil},
+		{"123.45", Decimal64{Mantissa: 12345, Exponent: -2}, nil},
+		{"1e-127", Decimal64{Mantissa: 1, Exponent: -127}, nil},
+		{"1e127", Decimal64{Mantissa: 1, Exponent: 127}, nil},
+		{"1e128", Decimal64{Mantissa: 10, Exponent: 127}, nil},
+		{fmt.Sprintf("%s", dfp.Max), Decimal64{Mantissa: 720575940379279350, Exponent: 127}, nil},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v := dfp.MustFromString(test.v)
+			d, err := NewDecimal64(v)
+			a.Equal(test.err, err)
+			a.Equal(test.expected, d)
+			if err != nil {
+				return
+			}
+			var b [Decimal64Size]byte
+			d.Put(b[:])
+			read := ReadDecimal64(b[:])
+			a.Equal(d, read)
+			back, valid, err := read.Value()
+			a.NoError(err)
+			a.True(valid)
+			a.True(v.Eq(back), "%v != %v", v, back)
+		})
+	}
+	a.True(NullDecimal64.IsNull())
+	_, valid, err := NullDecimal64.Value()
+	a.NoError(err)
+	a.False(valid)
+	_, _, err = Decimal64{Mantissa: -5, Exponent: 1}.Value()
+	a.EqualError(err, "negative value")
+}
+
+func TestDecimal32(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v        string
+		expected Decimal32
+		err      error
+	}{
+		{"0", Decimal32{}, nil},
+		{"123.45", Decimal32{Mantissa: 12345, Exponent: -2}, nil},
+		{"2147483647", Decimal32{Mantissa: 2147483647, Exponent: 0}, nil},
+		{"2147483648", Decimal32{Mantissa: 214748364, Exponent: 1}, dfp.ErrInexact},
+		{"12345678901.5", Decimal32{Mantissa: 1234567890, Exponent: 1}, dfp.ErrInexact},
+		{"1e128", Decimal32{Mantissa: 10, Exponent: 127}, nil},
+		{fmt.Sprintf("%s", dfp.Max), Decimal32{}, dfp.ErrOverflow},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			d, err := NewDecimal32(dfp.MustFromString(test.v))
+			a.Equal(test.err, err)
+			a.Equal(test.expected, d)
+			var b [Decimal32Size]byte
+			d.Put(b[:])
+			a.Equal(d, ReadDecimal32(b[:]))
+		})
+	}
+	a.True(NullDecimal32.IsNull())
+	_, valid, err := NullDecimal32.Value()
+	a.NoError(err)
+	a.False(valid)
+	v, valid, err := Decimal32{Mantissa: 15, Exponent: -1}.Value()
+	a.NoError(err)
+	a.True(valid)
+	a.Equal("1.5", v.String())
+}
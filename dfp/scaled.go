@@ -0,0 +1,88 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var (
+	// ErrOverflow is returned, when the result does not fit the destination type.
+	ErrOverflow = errors.New("overflow")
+	// ErrInexact is returned along with a valid result, when some digits were rounded or truncated.
+	ErrInexact = errors.New("inexact result")
+)
+
+// FromScaledInt64 returns a value for an integer with an implied number of decimal places,
+// so that the result is v * 10^-scale. For example, FromScaledInt64(12345, 4) is 1.2345.
+// Returns an error for negative values. If the least significant digits were truncated,
+// the truncated value is returned along with ErrInexact.
+// If the value is greater than Max, Max and ErrOverflow are returned.
+func FromScaledInt64(v int64, scale int) (Value, error) {
+	if v < 0 {
+		return zero, fmt.Errorf("negative value")
+	}
+	if v == 0 {
+		return zero, nil
+	}
+	m, e := uint64(v), -scale
+	var err error
+	for m > maxMantissa || e < minExponent {
+		if m%10 != 0 {
+			err = ErrInexact
+		}
+		if m /= 10; m == 0 {
+			return zero, ErrInexact
+		}
+		e++
+	}
+	for e > maxExponent {
+		if m*10 > maxMantissa {
+			return Max, ErrOverflow
+		}
+		m *= 10
+		e--
+	}
+	return fromMantAndExp(m, expType(e)).Normalized(), err
+}
+
+// ToScaledInt64 returns the value as an integer with scale implied decimal places,
+// so that the result is v * 10^scale. For example, 1.2345 with scale 4 is 12345.
+// If the value has more decimal places, it is rounded according to the mode,
+// and the rounded result is returned along with ErrInexact.
+// If the result overflows int64, 0 and ErrOverflow are returned.
+func (v Value) ToScaledInt64(scale int, mode RoundingMode) (int64, error) {
+	m, e := split(v)
+	if m == 0 {
+		return 0, nil
+	}
+	k := int(e) + scale
+	if k >= 0 {
+		p := pow10(k)
+		if p == 0 {
+			return 0, ErrOverflow
+		}
+		hi, lo := bits.Mul64(m, p)
+		if hi != 0 || lo > math.MaxInt64 {
+			return 0, ErrOverflow
+		}
+		return int64(lo), nil
+	}
+	q, r, p := uint64(0), m, pow10(-k)
+	if p != 0 {
+		q, r = m/p, m%p
+	} else {
+		// the divisor does not fit uint64, and it is certainly more, than twice the mantissa.
+		p = math.MaxUint64
+	}
+	if r == 0 {
+		return int64(q), nil
+	}
+	if roundUp(r, p, int(mode)) {
+		q++
+	}
+	return int64(q), ErrInexact
+}
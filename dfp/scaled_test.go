@@ -0,0 +1,102 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromScaledInt64(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v        int64
+		scale    int
+		expected string
+		err      error
+	}{
+		{0, 4, "0", nil},
+		{12345, 4, "1.2345", nil},
+		{12345, 0, "12345", nil},
+		{12345, -3, "12345000", nil},
+		{100000000, 8, "1", nil},
+		{1, 127, "0." + fmt.Sprintf("%0127d", 1), nil},
+		{10, 128, "0." + fmt.Sprintf("%0127d", 1), nil},
+		{1, 128, "0", ErrInexact},
+		{15, 128, "0." + fmt.Sprintf("%0127d", 1), ErrInexact},
+		{math.MaxInt64, 0, "9223372036854775000", ErrInexact},
+		{9223372036854775000, 4, "922337203685477.5", nil},
+		{1, -140, "1" + fmt.Sprintf("%0140d", 0), nil},
+		{1, -150, Max.String(), ErrOverflow},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v, err := FromScaledInt64(test.v, test.scale)
+			a.Equal(test.err, err)
+			a.Equal(test.expected, v.String())
+		})
+	}
+	_, err := FromScaledInt64(-1, 2)
+	a.EqualError(err, "negative value")
+}
+
+func TestToScaledInt64(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		v                         string
+		scale                     int
+		down, nearest, up, halfUp int64
+		err                       error
+	}{
+		{"0", 4, 0, 0, 0, 0, nil},
+		{"1.2345", 4, 12345, 12345, 12345, 12345, nil},
+		{"1.2345", 8, 123450000, 123450000, 123450000, 123450000, nil},
+		{"1.2345", 2, 123, 123, 124, 123, ErrInexact},
+		{"1.235", 2, 123, 123, 124, 124, ErrInexact},
+		{"1.2351", 2, 123, 124, 124, 124, ErrInexact},
+		{"12345", -2, 123, 123, 124, 123, ErrInexact},
+		{"1e-30", 4, 0, 0, 1, 0, ErrInexact},
+		{"9223372036854775807", 0, 9223372036854775e3, 9223372036854775e3, 9223372036854775e3, 9223372036854775e3, nil},
+		{"9223372036854775807", 1, 0, 0, 0, 0, ErrOverflow},
+		{"1e30", 0, 0, 0, 0, 0, ErrOverflow},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v := MustFromString(test.v)
+			for mode, expected := range map[RoundingMode]int64{
+				RoundDown:    test.down,
+				RoundNearest: test.nearest,
+				RoundUp:      test.up,
+				RoundHalfUp:  test.halfUp,
+			} {
+				result, err := v.ToScaledInt64(test.scale, mode)
+				a.Equal(test.err, err, "mode %d", mode)
+				a.Equal(expected, result, "mode %d", mode)
+			}
+		})
+	}
+	result, err := Min.ToScaledInt64(-10, RoundUp)
+	a.Equal(ErrInexact, err)
+	a.Equal(int64(1), result)
+}
+
+func TestScaledInt64Random(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		i64, scale := rnd.Int63n(maxMantissa), rnd.Intn(40)-20
+		v, err := FromScaledInt64(i64, scale)
+		if !a.NoError(err) {
+			break
+		}
+		result, err := v.ToScaledInt64(scale, RoundDown)
+		if !a.NoError(err) || !a.Equal(i64, result, "%d %d", i64, scale) {
+			break
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// shardPad is the size, which shards are padded to, so that they do not share cache lines.
+	shardPad = 128
+	// shardsPerProc is the default number of shards for every processor.
+	shardsPerProc = 4
+)
+
+// ShardedAccumulator is an Accumulator for concurrent use, which spreads additions across several shards,
+// so that goroutines running on different processors rarely contend for the same shard.
+// Every shard is an Accumulator protected by its own mutex. Its sum is exact, as Accumulator's one.
+type ShardedAccumulator struct {
+	shards []shard
+	mask   uint
+}
+
+type shardData struct {
+	mu  sync.Mutex
+	acc Accumulator
+}
+
+type shard struct {
+	shardData
+	_ [shardPad - unsafe.Sizeof(shardData{})%shardPad]byte
+}
+
+// NewShardedAccumulator returns an accumulator with at least n shards.
+// If n <= 0, the number of shards depends on GOMAXPROCS.
+func NewShardedAccumulator(n int) *ShardedAccumulator {
+	if n <= 0 {
+		n = shardsPerProc * runtime.GOMAXPROCS(0)
+	}
+	size := 1
+	for size < n {
+		size <<= 1
+	}
+	return &ShardedAccumulator{shards: make([]shard, size), mask: uint(size - 1)}
+}
+
+// Add adds a value to the sum.
+func (s *ShardedAccumulator) Add(v Value) {
+	sh := s.shard()
+	sh.mu.Lock()
+	sh.acc.Add(v)
+	sh.mu.Unlock()
+}
+
+// Sub subtracts a value from the sum.
+func (s *ShardedAccumulator) Sub(v Value) {
+	sh := s.shard()
+	sh.mu.Lock()
+	sh.acc.Sub(v)
+	sh.mu.Unlock()
+}
+
+// Sum returns a new accumulator containing the sum of all the shards.
+// The shards are locked one by one, so values, which are being added concurrently, may be not included.
+func (s *ShardedAccumulator) Sum() *Accumulator {
+	var result Accumulator
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		result.Merge(&sh.acc)
+		sh.mu.Unlock()
+	}
+	return &result
+}
+
+// Result returns the sum of all the shards rounded to prec decimal places. See Accumulator.Result for details.
+func (s *ShardedAccumulator) Result(prec int, mode RoundingMode) (v Value, neg bool, exact bool) {
+	return s.Sum().Result(prec, mode)
+}
+
+// Reset sets the sum to zero.
+func (s *ShardedAccumulator) Reset() {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		sh.acc.Reset()
+		sh.mu.Unlock()
+	}
+}
+
+// shard returns a shard for the calling goroutine.
+//
+// Go does not expose goroutine or processor ids, so the shard is chosen by the address of a local variable.
+// Every goroutine has its own stack, so concurrently running goroutines get different addresses,
+// and, with a good hash, mostly different shards. The address is not stable, as stacks can be moved,
+// but this only affects which shard is used, and never correctness: any shard can hold any value.
+func (s *ShardedAccumulator) shard() *shard {
+	var x byte
+	// drop the bits, which differ between the frames of the same goroutine, and mix the rest with Fibonacci hashing.
+	h := uint64(uintptr(unsafe.Pointer(&x))>>11) * 0x9e3779b97f4a7c15 >> 40
+	return &s.shards[uint(h)&s.mask]
+}
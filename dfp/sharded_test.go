@@ -0,0 +1,119 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedAccumulator(t *testing.T) {
+	a := assert.New(t)
+	s := NewShardedAccumulator(3)
+	a.Equal(4, len(s.shards))
+	a.True(len(NewShardedAccumulator(0).shards) > 0)
+
+	s.Add(MustFromString("1.5"))
+	s.Sub(MustFromString("2"))
+	v, neg, exact := s.Result(2, RoundDown)
+	a.Equal("0.5", v.String())
+	a.True(neg)
+	a.True(exact)
+	s.Reset()
+	a.True(s.Sum().IsZero())
+}
+
+func TestShardedAccumulatorConcurrent(t *testing.T) {
+	a := assert.New(t)
+	const (
+		goroutines = 16
+		iterations = 10000
+	)
+	s := NewShardedAccumulator(0)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				s.Add(FromMantAndExp(1, -int32(j%3)))
+				if j%2 == 0 {
+					s.Sub(MustFromString("0.01"))
+				}
+				if j%1000 == 0 {
+					s.Sum()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	// every goroutine adds 3334 * 1 + 3333 * 0.1 + 3333 * 0.01 - 5000 * 0.01 = 3650.63.
+	v, neg, exact := s.Result(10, RoundDown)
+	a.Equal("58410.08", v.String())
+	a.False(neg)
+	a.True(exact)
+}
+
+func TestShardedAccumulatorConcurrentSum(t *testing.T) {
+	a := assert.New(t)
+	const (
+		goroutines = 8
+		iterations = 5000
+	)
+	s := NewShardedAccumulator(0)
+	delta := MustFromString("0.01")
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				s.Add(delta)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// every shard only grows, so every next sum is not less, than the previous one.
+		var prev Value
+		for i := 0; i < 1000; i++ {
+			v, neg, exact := s.Result(2, RoundDown)
+			if !a.False(neg) || !a.True(exact) || !a.True(v.Cmp(prev) >= 0, "%v < %v", v, prev) {
+				return
+			}
+			prev = v
+		}
+	}()
+	wg.Wait()
+	<-done
+	v, _, _ := s.Result(2, RoundDown)
+	a.Equal("400", v.String())
+}
+
+func BenchmarkShardedAccumulatorAdd(b *testing.B) {
+	s := NewShardedAccumulator(0)
+	delta := MustFromString("0.01")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Add(delta)
+		}
+	})
+}
+
+func BenchmarkMutexAccumulatorAdd(b *testing.B) {
+	var (
+		mu  sync.Mutex
+		acc Accumulator
+	)
+	delta := MustFromString("0.01")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.Lock()
+			acc.Add(delta)
+			mu.Unlock()
+		}
+	})
+}
@@ -0,0 +1,330 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package sketch implements a mergeable quantile sketch for decimal floating-point values.
+//
+// Like DDSketch, it counts values in buckets, so that a quantile is estimated with a relative accuracy alpha:
+// if the true quantile is x, the estimate is within x * alpha of it. Unlike DDSketch, the buckets are not
+// logarithmic, but decimal: a value is split into the exponent of its most significant digit
+// and a dfp.KeyCoeffDigits-digit coefficient, and the coefficients of every decade are divided
+// into buckets of equal width.
+// So the bucket boundaries and the estimates are exact decimals, and no float64 conversions are performed.
+// The price is the number of buckets, which is about 4.5 / alpha for every decade of values.
+//
+// For values less than 1e-112 the middles of the buckets have more decimal places, than dfp.Min,
+// so the estimates are additionally rounded to the precision of dfp.Value.
+package sketch
+
+import (
+	"encoding/binary"
+	"fmt"
+	gosort "sort"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	version = 1
+
+	// coeffDigits is the number of digits in a coefficient.
+	coeffDigits = dfp.KeyCoeffDigits
+)
+
+var (
+	minAlpha = dfp.MustFromString("1e-15")
+	one      = dfp.FromUint64(1)
+
+	// minCoeff and maxCoeff are the bounds of a coefficient.
+	minCoeff = dfp.FromMantAndExp(1, coeffDigits-1).Uint64()
+	maxCoeff = minCoeff * 10
+	// minExp and maxExp are the bounds of the exponent of the most significant digit.
+	minExp, _ = split(dfp.Min)
+	maxExp, _ = split(dfp.Max)
+)
+
+// Sketch is a quantile sketch. The zero value is not usable, use New or UnmarshalBinary.
+// A sketch is not safe for concurrent use, but sketches, filled by different goroutines or processes,
+// can be merged.
+type Sketch struct {
+	alpha dfp.Value
+	// width is the width of a bucket in coefficient units, and perDecade is the number of buckets in a decade.
+	width, perDecade uint64
+	zeros, count     uint64
+	min, max         dfp.Value
+	buckets          map[int64]uint64
+}
+
+// New returns a sketch with relative accuracy alpha, which must be in [1e-15, 1).
+func New(alpha dfp.Value) (*Sketch, error) {
+	s := &Sketch{buckets: make(map[int64]uint64)}
+	if err := s.init(alpha); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Sketch) init(alpha dfp.Value) error {
+	if alpha.Cmp(minAlpha) < 0 || alpha.Cmp(one) >= 0 {
+		return fmt.Errorf("bad relative accuracy: %v", alpha)
+	}
+	// a value is at most width/2 units away from the middle of its bucket, which should not exceed alpha * minCoeff.
+	// width is a multiple of 20, so that the middles are multiples of 10, and fit the mantissa.
+	units, err := alpha.ToScaledInt64(coeffDigits-2, dfp.RoundDown)
+	if err != nil && err != dfp.ErrInexact {
+		return err
+	}
+	s.alpha = alpha
+	s.width = 20 * uint64(units)
+	s.perDecade = (maxCoeff - minCoeff + s.width - 1) / s.width
+	return nil
+}
+
+// Alpha returns the relative accuracy.
+func (s *Sketch) Alpha() dfp.Value {
+	return s.alpha
+}
+
+// Count returns the number of values.
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Min returns the minimum value, or zero, if the sketch is empty.
+func (s *Sketch) Min() dfp.Value {
+	return s.min
+}
+
+// Max returns the maximum value, or zero, if the sketch is empty.
+func (s *Sketch) Max() dfp.Value {
+	return s.max
+}
+
+// Add adds a value.
+func (s *Sketch) Add(v dfp.Value) {
+	s.AddN(v, 1)
+}
+
+// AddN adds a value n times.
+func (s *Sketch) AddN(v dfp.Value, n uint64) {
+	if n == 0 {
+		return
+	}
+	if s.count == 0 || v.Cmp(s.min) < 0 {
+		s.min = v
+	}
+	if s.count == 0 || v.Cmp(s.max) > 0 {
+		s.max = v
+	}
+	s.count += n
+	if v.IsZero() {
+		s.zeros += n
+		return
+	}
+	s.buckets[s.index(v)] += n
+}
+
+// Quantile returns an estimate of q-quantile, where q is in [0, 1].
+// The estimate is within alpha * x of the true quantile x, and it is never out of [Min, Max].
+// The quantile of the smallest value, which has rank q * (Count - 1) rounded down, is returned.
+func (s *Sketch) Quantile(q dfp.Value) (dfp.Value, error) {
+	if q.Cmp(one) > 0 {
+		return 0, fmt.Errorf("bad quantile: %v", q)
+	}
+	if s.count == 0 {
+		return 0, fmt.Errorf("empty sketch")
+	}
+	rank := q.Mul(dfp.FromUint64(s.count - 1)).Uint64()
+	if rank == 0 {
+		return s.min, nil
+	}
+	if rank == s.count-1 {
+		return s.max, nil
+	}
+	if rank < s.zeros {
+		return 0, nil
+	}
+	keys := s.sortedKeys()
+	seen := s.zeros
+	for _, key := range keys {
+		if seen += s.buckets[key]; seen > rank {
+			v := s.value(key)
+			if v.Cmp(s.min) < 0 {
+				v = s.min
+			}
+			if v.Cmp(s.max) > 0 {
+				v = s.max
+			}
+			return v, nil
+		}
+	}
+	return s.max, nil
+}
+
+// Merge adds all the values of another sketch, which must have the same relative accuracy.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.width != other.width {
+		return fmt.Errorf("sketches have different relative accuracy: %v and %v", s.alpha, other.alpha)
+	}
+	if other.count == 0 {
+		return nil
+	}
+	if s.count == 0 || other.min.Cmp(s.min) < 0 {
+		s.min = other.min
+	}
+	if s.count == 0 || other.max.Cmp(s.max) > 0 {
+		s.max = other.max
+	}
+	s.count += other.count
+	s.zeros += other.zeros
+	for key, n := range other.buckets {
+		s.buckets[key] += n
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// The data contains a version byte, the relative accuracy, counters, and the buckets in increasing order.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	data := []byte{version}
+	data = appendUvarint(data, uint64(s.alpha))
+	data = appendUvarint(data, s.zeros)
+	data = appendUvarint(data, uint64(s.min))
+	data = appendUvarint(data, uint64(s.max))
+	keys := s.sortedKeys()
+	data = appendUvarint(data, uint64(len(keys)))
+	var prev int64
+	for _, key := range keys {
+		data = appendUvarint(data, zigzag(key-prev))
+		data = appendUvarint(data, s.buckets[key])
+		prev = key
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty data")
+	}
+	if data[0] != version {
+		return fmt.Errorf("unsupported version %d", data[0])
+	}
+	d := decoder{data: data[1:]}
+	alpha, zeros, min, max, n := d.uvarint(), d.uvarint(), d.uvarint(), d.uvarint(), d.uvarint()
+	if d.err != nil {
+		return d.err
+	}
+	var result Sketch
+	if err := result.init(dfp.Value(alpha)); err != nil {
+		return err
+	}
+	result.zeros, result.count = zeros, zeros
+	result.min, result.max = dfp.Value(min), dfp.Value(max)
+	result.buckets = make(map[int64]uint64)
+	var key int64
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		delta := unzigzag(d.uvarint())
+		key += delta
+		count := d.uvarint()
+		// the keys are written in increasing order, so all the deltas, but the first one, are positive.
+		if exp := result.exp(key); count == 0 || i > 0 && delta <= 0 || exp < minExp || exp > maxExp {
+			return fmt.Errorf("corrupted data")
+		}
+		result.buckets[key] = count
+		result.count += count
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return fmt.Errorf("corrupted data: %d extra bytes", len(d.data))
+	}
+	// the minimum and the maximum are zero for an empty sketch.
+	if result.count == 0 && (result.min != 0 || result.max != 0) || result.min.Cmp(result.max) > 0 {
+		return fmt.Errorf("corrupted data: bad minimum or maximum")
+	}
+	*s = result
+	return nil
+}
+
+// index returns the index of the bucket for a non-zero value.
+// Indexes increase with values, and every decade has perDecade buckets.
+func (s *Sketch) index(v dfp.Value) int64 {
+	exp, coeff := split(v)
+	return exp*int64(s.perDecade) + int64((coeff-minCoeff)/s.width)
+}
+
+// exp returns the exponent of the most significant digit for the values in the bucket.
+func (s *Sketch) exp(index int64) int64 {
+	exp := index / int64(s.perDecade)
+	if index%int64(s.perDecade) < 0 {
+		exp--
+	}
+	return exp
+}
+
+// value returns the middle of the bucket.
+func (s *Sketch) value(index int64) dfp.Value {
+	exp := s.exp(index)
+	i := index - exp*int64(s.perDecade)
+	lower := minCoeff + uint64(i)*s.width
+	upper := lower + s.width
+	if upper > maxCoeff {
+		upper = maxCoeff
+	}
+	m, e := (lower+(upper-lower)/2)/10, exp-(coeffDigits-2)
+	if e < minExp {
+		// round the middle to the precision of dfp.Value.
+		shift := dfp.FromMantAndExp(1, int32(minExp-e))
+		m, e = dfp.FromUint64(m).Quo(shift, 0, dfp.RoundHalfUp).Uint64(), minExp
+	}
+	return dfp.FromMantAndExp(m, int32(e))
+}
+
+func (s *Sketch) sortedKeys() []int64 {
+	keys := make([]int64, 0, len(s.buckets))
+	for key := range s.buckets {
+		keys = append(keys, key)
+	}
+	gosort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// split returns the exponent of the most significant digit of a non-zero value,
+// and its coeffDigits-digit coefficient.
+func split(v dfp.Value) (exp int64, coeff uint64) {
+	e, coeff := v.KeyParts()
+	return int64(e), coeff
+}
+
+// decoder reads varints, and remembers the first error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = fmt.Errorf("corrupted data")
+		return 0
+	}
+	d.data = d.data[n:]
+	return x
+}
+
+func appendUvarint(dst []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(dst, buf[:n]...)
+}
+
+func zigzag(x int64) uint64 {
+	return uint64(x<<1) ^ uint64(x>>63)
+}
+
+func unzigzag(x uint64) int64 {
+	return int64(x>>1) ^ -int64(x&1)
+}
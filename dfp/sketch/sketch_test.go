@@ -0,0 +1,195 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package sketch
+
+import (
+	"math/big"
+	"math/rand"
+	gosort "sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+// randomValues returns random values with exponents in [minExp, 128].
+func randomValues(rnd *rand.Rand, n int, minExp int) []dfp.Value {
+	values := make([]dfp.Value, n)
+	for i := range values {
+		values[i] = dfp.FromMantAndExp(uint64(rnd.Int63n(1<<56))>>uint(rnd.Intn(56)), int32(rnd.Intn(129-minExp)+minExp))
+	}
+	return values
+}
+
+func TestNew(t *testing.T) {
+	a := assert.New(t)
+	for _, alpha := range []string{"0", "1", "1.5", "0.0000000000000001"} {
+		_, err := New(dfp.MustFromString(alpha))
+		a.Error(err, alpha)
+	}
+	s, err := New(dfp.MustFromString("0.01"))
+	a.NoError(err)
+	a.Equal(uint64(200000000000000), s.width)
+	a.Equal(uint64(450), s.perDecade)
+	a.Equal("0.01", s.Alpha().String())
+	s, err = New(dfp.MustFromString("0.0123"))
+	a.NoError(err)
+	a.Equal(uint64(246000000000000), s.width)
+	a.Equal(uint64(366), s.perDecade)
+	s, err = New(dfp.MustFromString("1e-15"))
+	a.NoError(err)
+	a.Equal(uint64(20), s.width)
+}
+
+func TestQuantile(t *testing.T) {
+	a := assert.New(t)
+	s, _ := New(dfp.MustFromString("0.01"))
+	_, err := s.Quantile(dfp.MustFromString("0.5"))
+	a.EqualError(err, "empty sketch")
+	s.Add(dfp.MustFromString("1.5"))
+	_, err = s.Quantile(dfp.MustFromString("1.01"))
+	a.EqualError(err, "bad quantile: 101e-2")
+	for _, q := range []string{"0", "0.5", "1"} {
+		v, err := s.Quantile(dfp.MustFromString(q))
+		a.NoError(err)
+		a.Equal("1.5", v.String())
+	}
+
+	s.AddN(dfp.MustFromString("0"), 2)
+	s.AddN(dfp.MustFromString("100"), 0)
+	s.Add(dfp.MustFromString("123.456"))
+	a.Equal(uint64(4), s.Count())
+	a.Equal("0", s.Min().String())
+	a.Equal("123.456", s.Max().String())
+	// 1.5 is in the bucket [1.5, 1.52).
+	expected := map[string]string{"0": "0", "0.33": "0", "0.34": "0", "0.67": "1.51", "0.99": "1.51", "1": "123.456"}
+	for q, e := range expected {
+		v, err := s.Quantile(dfp.MustFromString(q))
+		a.NoError(err)
+		a.Equal(e, v.String(), q)
+	}
+}
+
+func TestAccuracy(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for _, alpha := range []string{"0.5", "0.05", "0.0123", "0.001", "1e-15"} {
+		s, err := New(dfp.MustFromString(alpha))
+		a.NoError(err)
+		// the smallest values are rounded to the precision of dfp.Value.
+		values := randomValues(rnd, 10000, -111)
+		for _, v := range values {
+			s.Add(v)
+		}
+		gosort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+		for i := 0; i <= 100; i++ {
+			q := dfp.FromMantAndExp(uint64(i), -2)
+			v, err := s.Quantile(q)
+			a.NoError(err)
+			exact := values[q.Mul(dfp.FromUint64(uint64(len(values)-1))).Uint64()]
+			diff := new(big.Rat).Sub(v.BigRat(), exact.BigRat())
+			bound := new(big.Rat).Mul(s.Alpha().BigRat(), exact.BigRat())
+			a.True(diff.Abs(diff).Cmp(bound) <= 0, "alpha %s, q %v: %v != %v", alpha, q, v, exact)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	alpha := dfp.MustFromString("0.02")
+	all, _ := New(alpha)
+	merged, _ := New(alpha)
+	for i := 0; i < 4; i++ {
+		part, _ := New(alpha)
+		for _, v := range randomValues(rnd, 1000, -127) {
+			part.Add(v)
+			all.Add(v)
+		}
+		part.AddN(0, uint64(i))
+		all.AddN(0, uint64(i))
+		a.NoError(merged.Merge(part))
+	}
+	empty, _ := New(alpha)
+	a.NoError(merged.Merge(empty))
+	a.Equal(all.Count(), merged.Count())
+	expected, _ := all.MarshalBinary()
+	data, _ := merged.MarshalBinary()
+	a.Equal(expected, data)
+
+	other, _ := New(dfp.MustFromString("0.01"))
+	a.EqualError(merged.Merge(other), "sketches have different relative accuracy: 2e-2 and 1e-2")
+}
+
+func TestMarshalBinary(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	s, _ := New(dfp.MustFromString("0.01"))
+	data, err := s.MarshalBinary()
+	a.NoError(err)
+	var empty Sketch
+	a.NoError(empty.UnmarshalBinary(data))
+	a.Equal(uint64(0), empty.Count())
+
+	for _, v := range randomValues(rnd, 1000, -127) {
+		s.Add(v)
+	}
+	s.AddN(dfp.Min, 5)
+	s.Add(dfp.Max)
+	s.Add(0)
+	data, err = s.MarshalBinary()
+	a.NoError(err)
+	var decoded Sketch
+	a.NoError(decoded.UnmarshalBinary(data))
+	a.Equal(s.Count(), decoded.Count())
+	a.True(s.Min().Eq(decoded.Min()))
+	a.True(s.Max().Eq(decoded.Max()))
+	for i := 0; i <= 100; i++ {
+		q := dfp.FromMantAndExp(uint64(i), -2)
+		expected, _ := s.Quantile(q)
+		v, _ := decoded.Quantile(q)
+		a.True(expected.Eq(v), "%v: %v != %v", q, expected, v)
+	}
+	again, _ := decoded.MarshalBinary()
+	a.Equal(data, again)
+
+	a.EqualError(decoded.UnmarshalBinary(nil), "empty data")
+	a.EqualError(decoded.UnmarshalBinary([]byte{2}), "unsupported version 2")
+	a.EqualError(decoded.UnmarshalBinary(data[:len(data)-1]), "corrupted data")
+	a.EqualError(decoded.UnmarshalBinary(append(data, 0)), "corrupted data: 1 extra bytes")
+	a.Equal(s.Count(), decoded.Count())
+
+	// encode writes the header and the buckets as pairs of key deltas and counts.
+	encode := func(min, max dfp.Value, buckets ...int64) []byte {
+		data := []byte{version}
+		for _, u := range []uint64{uint64(s.alpha), 0, uint64(min), uint64(max), uint64(len(buckets) / 2)} {
+			data = appendUvarint(data, u)
+		}
+		for i := 0; i < len(buckets); i += 2 {
+			data = appendUvarint(data, zigzag(buckets[i]))
+			data = appendUvarint(data, uint64(buckets[i+1]))
+		}
+		return data
+	}
+	one, two := dfp.FromUint64(1), dfp.FromUint64(2)
+	key := s.index(one)
+	a.NoError(decoded.UnmarshalBinary(encode(one, two, key, 1, s.index(two)-key, 1)))
+	a.Equal(uint64(2), decoded.Count())
+	a.EqualError(decoded.UnmarshalBinary(encode(one, two, key, 1, 0, 1)), "corrupted data")
+	a.EqualError(decoded.UnmarshalBinary(encode(one, two, key, 1, -1, 1)), "corrupted data")
+	a.EqualError(decoded.UnmarshalBinary(encode(two, one, key, 1)), "corrupted data: bad minimum or maximum")
+	a.EqualError(decoded.UnmarshalBinary(encode(0, one)), "corrupted data: bad minimum or maximum")
+	a.EqualError(decoded.UnmarshalBinary(encode(one, 0)), "corrupted data: bad minimum or maximum")
+	a.Equal(uint64(2), decoded.Count())
+}
+
+func BenchmarkAdd(b *testing.B) {
+	values := randomValues(rand.New(rand.NewSource(1)), 10000, -127)
+	s, _ := New(dfp.MustFromString("0.01"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Add(values[i%len(values)])
+	}
+}
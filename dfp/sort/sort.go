@@ -0,0 +1,127 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package sort implements sorting and searching of dfp.Value slices.
+// Sort precomputes an order-preserving key for every value with dfp.Value.AppendKey,
+// and sorts the keys with a radix sort. Slices shorter, than radixThreshold, are sorted with sort.Sort,
+// which compares values with dfp.Value.Cmp, as Search does.
+package sort
+
+import (
+	"encoding/binary"
+	gosort "sort"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+const (
+	// radixThreshold is the length, starting from which radix sort is used.
+	radixThreshold = 256
+	// radixBits is the size of a radix digit.
+	radixBits = 11
+	// radixPasses is the number of passes needed to sort dfp.KeySize-byte keys.
+	radixPasses = (dfp.KeySize*8 + radixBits - 1) / radixBits
+)
+
+// Values implements sort.Interface for a slice of values in increasing order.
+type Values []dfp.Value
+
+func (v Values) Len() int           { return len(v) }
+func (v Values) Less(i, j int) bool { return v[i].Cmp(v[j]) < 0 }
+func (v Values) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+
+// Sort sorts the slice in increasing order.
+func (v Values) Sort() { Sort(v) }
+
+// Search returns the result of applying Search to the slice.
+func (v Values) Search(x dfp.Value) int { return Search(v, x) }
+
+// Sort sorts a slice of values in increasing order.
+// Equal values with different mantissas and exponents can be reordered.
+func Sort(values []dfp.Value) {
+	if len(values) < radixThreshold {
+		gosort.Sort(Values(values))
+		return
+	}
+	items := make([]item, len(values))
+	for i, v := range values {
+		items[i] = makeItem(v)
+	}
+	items = radixSort(items, make([]item, len(items)))
+	for i, it := range items {
+		values[i] = it.v
+	}
+}
+
+// IsSorted reports whether the slice is sorted in increasing order.
+func IsSorted(values []dfp.Value) bool {
+	for i := len(values) - 1; i > 0; i-- {
+		if values[i].Cmp(values[i-1]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Search searches for x in a sorted slice of values, and returns the index as specified by sort.Search.
+// The return value is the index to insert x, if x is not present. It can be len(values).
+func Search(values []dfp.Value, x dfp.Value) int {
+	return gosort.Search(len(values), func(i int) bool {
+		return values[i].Cmp(x) >= 0
+	})
+}
+
+// item holds a value and its key, where hi is the first byte of the key, and lo holds the rest.
+type item struct {
+	lo uint64
+	v  dfp.Value
+	hi uint8
+}
+
+func makeItem(v dfp.Value) item {
+	var buf [dfp.KeySize]byte
+	key := v.AppendKey(buf[:0])
+	return item{lo: binary.BigEndian.Uint64(key[1:]), hi: key[0], v: v}
+}
+
+func (it item) digit(pass int) int {
+	shift := uint(pass * radixBits)
+	switch {
+	case shift+radixBits <= 64:
+		return int(it.lo>>shift) & (1<<radixBits - 1)
+	case shift < 64:
+		return int(it.lo>>shift|uint64(it.hi)<<(64-shift)) & (1<<radixBits - 1)
+	default:
+		return int(it.hi>>(shift-64)) & (1<<radixBits - 1)
+	}
+}
+
+// radixSort sorts items using buf as a temporary storage, and returns the sorted slice,
+// which is either items or buf.
+func radixSort(items, buf []item) []item {
+	// the histograms of all the passes are calculated at once.
+	var counts [radixPasses][1 << radixBits]int
+	for _, it := range items {
+		for pass := range counts {
+			counts[pass][it.digit(pass)]++
+		}
+	}
+	for pass := range counts {
+		offsets := &counts[pass]
+		// all the items have the same digit, so the pass does not change the order.
+		if offsets[items[0].digit(pass)] == len(items) {
+			continue
+		}
+		offset := 0
+		for i, c := range offsets {
+			offsets[i] = offset
+			offset += c
+		}
+		for _, it := range items {
+			d := it.digit(pass)
+			buf[offsets[d]] = it
+			offsets[d]++
+		}
+		items, buf = buf, items
+	}
+	return items
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package sort
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	gosort "sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func randomValues(rnd *rand.Rand, n int) []dfp.Value {
+	values := make([]dfp.Value, n)
+	for i := range values {
+		m := uint64(rnd.Int63n(1<<56)) >> uint(rnd.Intn(56))
+		values[i] = dfp.FromMantAndExp(m, int32(rnd.Intn(40)-20))
+	}
+	return values
+}
+
+func TestKey(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	values := append(randomValues(rnd, 10000), dfp.Value(0), dfp.Min, dfp.Max)
+	for i := 1; i < len(values); i++ {
+		v1, v2 := values[i-1], values[i]
+		if !a.Equal(v1.Cmp(v2), bytes.Compare(v1.AppendKey(nil), v2.AppendKey(nil)), "%v %v", v1, v2) {
+			break
+		}
+	}
+	// the digits of all the passes make up the key.
+	for _, v := range values {
+		it := makeItem(v)
+		key := make([]byte, dfp.KeySize)
+		key[0] = it.hi
+		binary.BigEndian.PutUint64(key[1:], it.lo)
+		a.Equal(v.AppendKey(nil), key, "%v", v)
+		var hi, lo uint64
+		for pass := radixPasses - 1; pass >= 0; pass-- {
+			d := uint64(it.digit(pass))
+			hi = hi<<radixBits | lo>>(64-radixBits)
+			lo = lo<<radixBits | d
+		}
+		a.Equal(uint64(it.hi), hi, "%v", v)
+		a.Equal(it.lo, lo, "%v", v)
+	}
+}
+
+func TestSort(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for _, n := range []int{0, 1, 10, radixThreshold - 1, radixThreshold, 1000, 10000} {
+		values := randomValues(rnd, n)
+		if n > 2 {
+			// equal values with different representations.
+			values[0] = dfp.FromMantAndExp(15, -1).ToExp(-5)
+			values[1] = dfp.MustFromString("1.5")
+		}
+		expected := make([]dfp.Value, len(values))
+		copy(expected, values)
+		gosort.Slice(expected, func(i, j int) bool { return expected[i].Cmp(expected[j]) < 0 })
+		Sort(values)
+		a.True(IsSorted(values))
+		for i := range values {
+			if !a.True(expected[i].Eq(values[i]), "%d: %v != %v", i, expected[i], values[i]) {
+				break
+			}
+		}
+	}
+	a.False(IsSorted([]dfp.Value{dfp.MustFromString("2"), dfp.MustFromString("1")}))
+
+	values := Values{dfp.MustFromString("3"), dfp.MustFromString("1"), dfp.MustFromString("2")}
+	values.Sort()
+	a.Equal("1 2 3", values[0].String()+" "+values[1].String()+" "+values[2].String())
+	a.True(gosort.IsSorted(values))
+}
+
+func TestSearch(t *testing.T) {
+	a := assert.New(t)
+	values := Values{dfp.MustFromString("1"), dfp.MustFromString("1.5"), dfp.MustFromString("1.5"), dfp.MustFromString("100")}
+	tests := []struct {
+		x        dfp.Value
+		expected int
+	}{
+		{dfp.Value(0), 0},
+		{dfp.MustFromString("1"), 0},
+		{dfp.MustFromString("1.2"), 1},
+		{dfp.FromMantAndExp(15, -1).ToExp(-10), 1},
+		{dfp.MustFromString("1e2"), 3},
+		{dfp.MustFromString("1000"), 4},
+	}
+	for _, test := range tests {
+		a.Equal(test.expected, values.Search(test.x), "%v", test.x)
+	}
+	a.Equal(0, Search(nil, dfp.Max))
+}
+
+func benchmarkValues() []dfp.Value {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]dfp.Value, 1000000)
+	for i := range values {
+		// prices with 2-6 decimal places.
+		values[i] = dfp.FromMantAndExp(uint64(rnd.Int63n(100000000)), int32(-rnd.Intn(5)-2))
+	}
+	return values
+}
+
+func BenchmarkSort(b *testing.B) {
+	values := benchmarkValues()
+	toSort := make([]dfp.Value, len(values))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(toSort, values)
+		Sort(toSort)
+	}
+}
+
+func BenchmarkSortSlice(b *testing.B) {
+	values := benchmarkValues()
+	toSort := make([]dfp.Value, len(values))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(toSort, values)
+		gosort.Slice(toSort, func(i, j int) bool { return toSort[i].Cmp(toSort[j]) < 0 })
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+// Package stats implements descriptive statistics over decimal floating-point values.
+// All the calculations are exact, and only the results are rounded to prec decimal places using given rounding mode.
+// If a result has more digits, than the mantissa can hold, the least significant digits are also rounded.
+// If a result overflows dfp.Max, dfp.Max is returned.
+package stats
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/avdva/numeric/dfp"
+	dfpsort "github.com/avdva/numeric/dfp/sort"
+)
+
+var (
+	maxMantissa = dfp.Max.MantUint64()
+	// mantDigits is the number of decimal digits in dfp.Max mantissa.
+	mantDigits = len(strconv.FormatUint(maxMantissa, 10))
+	// maxPrec is the number of decimal places of dfp.Min.
+	maxPrec = -int(dfp.Min.Exp())
+
+	bigOne = big.NewInt(1)
+	// bigPowers is a table of small powers of 10.
+	bigPowers [39]*big.Int
+)
+
+func init() {
+	bigPowers[0] = big.NewInt(1)
+	for i := 1; i < len(bigPowers); i++ {
+		bigPowers[i] = new(big.Int).Mul(bigPowers[i-1], big.NewInt(10))
+	}
+}
+
+// Mean returns the arithmetic mean of values.
+func Mean(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return stream(values).Mean(prec, mode)
+}
+
+// Variance returns the population variance of values.
+func Variance(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return stream(values).Variance(prec, mode)
+}
+
+// SampleVariance returns the sample variance of values. At least two values are required.
+func SampleVariance(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return stream(values).SampleVariance(prec, mode)
+}
+
+// StdDev returns the population standard deviation of values.
+func StdDev(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return stream(values).StdDev(prec, mode)
+}
+
+// SampleStdDev returns the sample standard deviation of values. At least two values are required.
+func SampleStdDev(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return stream(values).SampleStdDev(prec, mode)
+}
+
+// Median returns the median of values. For an even number of values it is the mean of the two middle ones.
+func Median(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	return Percentile(values, dfp.Percent(dfp.FromUint64(50)), prec, mode)
+}
+
+// Percentile returns p-th percentile of values, where p is between 0% and 100%.
+// If the percentile falls between two values, it is linearly interpolated.
+func Percentile(values []dfp.Value, p dfp.Percent, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no values")
+	}
+	if p.Value().Cmp(dfp.FromUint64(100)) > 0 {
+		return 0, fmt.Errorf("bad percentile: %v", p)
+	}
+	sorted := make([]dfp.Value, len(values))
+	copy(sorted, values)
+	dfpsort.Sort(sorted)
+	// rank = p / 100 * (n - 1), and the result is sorted[i] + frac(rank) * (sorted[i+1] - sorted[i]).
+	rank := new(big.Rat).Mul(p.Value().BigRat(), big.NewRat(int64(len(values)-1), 100))
+	i := new(big.Int).Quo(rank.Num(), rank.Denom()).Int64()
+	result := sorted[i].BigRat()
+	if frac := rank.Sub(rank, new(big.Rat).SetInt64(i)); frac.Sign() > 0 {
+		diff := new(big.Rat).Sub(sorted[i+1].BigRat(), result)
+		result.Add(result, diff.Mul(diff, frac))
+	}
+	return round(result, prec, mode), nil
+}
+
+// WeightedMean returns the mean of values weighted by weights.
+func WeightedMean(values, weights []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	if len(values) != len(weights) {
+		return 0, fmt.Errorf("values and weights have different lengths: %d and %d", len(values), len(weights))
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no values")
+	}
+	var sum, total dfp.Accumulator
+	for i, v := range values {
+		sum.AddMul(v, weights[i])
+		total.Add(weights[i])
+	}
+	if total.IsZero() {
+		return 0, fmt.Errorf("zero total weight")
+	}
+	result, _, _ := sum.Quo(&total, prec, mode)
+	return result, nil
+}
+
+func stream(values []dfp.Value) *Stream {
+	var s Stream
+	for _, v := range values {
+		s.Add(v)
+	}
+	return &s
+}
+
+// round returns non-negative r rounded to prec decimal places.
+func round(r *big.Rat, prec int, mode dfp.RoundingMode) dfp.Value {
+	v, _, _ := dfp.FromBigRat(r, mode)
+	// v is rounded to the precision of the mantissa. If it has no more, than prec decimal places,
+	// rounding to prec places gives the same result.
+	if int(v.Exp()) >= -prec {
+		return v
+	}
+	num, den := scale(r, prec)
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if roundUp(rem, den, mode) {
+		q.Add(q, bigOne)
+	}
+	return dfp.FromMantAndExp(q.Uint64(), -int32(prec))
+}
+
+// Sqrt returns the square root of r rounded to prec decimal places. r must not be negative.
+// It allows calculating deviations from exact sums, which are kept outside of this package.
+func Sqrt(r *big.Rat, prec int, mode dfp.RoundingMode) dfp.Value {
+	// estimate the number of integer digits of the root, and start from a number of decimal places,
+	// which certainly does not fit the mantissa, decreasing it until the root fits.
+	digits := (bitsToDigits(r.Num().BitLen()) - bitsToDigits(r.Denom().BitLen()) + 1) / 2
+	k := mantDigits - digits + 2
+	if k > prec {
+		k = prec
+	}
+	if k > maxPrec {
+		k = maxPrec
+	}
+	var s, sq, rem, lhs, rhs big.Int
+	for ; ; k-- {
+		num, den := scale(r, 2*k)
+		s.Sqrt(s.Quo(num, den))
+		// the root is exact, if s^2 = num / den.
+		rem.Sub(num, sq.Mul(sq.Mul(&s, &s), den))
+		up := false
+		if rem.Sign() != 0 {
+			switch mode {
+			case dfp.RoundUp:
+				up = true
+			case dfp.RoundNearest, dfp.RoundHalfUp:
+				// compare num / den with (s + 1/2)^2, that is 4 * num with (2s + 1)^2 * den.
+				lhs.Lsh(num, 2)
+				rhs.Lsh(&s, 1).Add(&rhs, bigOne)
+				rhs.Mul(&rhs, &rhs).Mul(&rhs, den)
+				cmp := lhs.Cmp(&rhs)
+				up = cmp > 0 || cmp == 0 && mode == dfp.RoundHalfUp
+			}
+		}
+		if up {
+			s.Add(&s, bigOne)
+		}
+		if s.IsUint64() && s.Uint64() <= maxMantissa {
+			return dfp.FromMantAndExp(s.Uint64(), -int32(k))
+		}
+	}
+}
+
+// scale returns numerator and denominator of r * 10^pow.
+func scale(r *big.Rat, pow int) (num, den *big.Int) {
+	if pow >= 0 {
+		return new(big.Int).Mul(r.Num(), pow10(pow)), r.Denom()
+	}
+	return r.Num(), new(big.Int).Mul(r.Denom(), pow10(-pow))
+}
+
+// roundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func roundUp(r, d *big.Int, mode dfp.RoundingMode) bool {
+	if r.Sign() == 0 {
+		return false
+	}
+	switch mode {
+	case dfp.RoundNearest:
+		return new(big.Int).Lsh(r, 1).Cmp(d) > 0
+	case dfp.RoundHalfUp:
+		return new(big.Int).Lsh(r, 1).Cmp(d) >= 0
+	case dfp.RoundUp:
+		return true
+	default:
+		return false
+	}
+}
+
+// bitsToDigits returns the number of decimal digits in a number with given bit length, or one less.
+func bitsToDigits(bitLen int) int {
+	return bitLen * 1233 >> 12
+}
+
+// pow10 returns 10^pow. The result must not be modified.
+func pow10(pow int) *big.Int {
+	if pow < len(bigPowers) {
+		return bigPowers[pow]
+	}
+	return new(big.Int).Exp(bigPowers[1], big.NewInt(int64(pow)), nil)
+}
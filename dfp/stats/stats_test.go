@@ -0,0 +1,205 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package stats
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func values(strs ...string) []dfp.Value {
+	result := make([]dfp.Value, len(strs))
+	for i, s := range strs {
+		result[i] = dfp.MustFromString(s)
+	}
+	return result
+}
+
+type statFunc func(values []dfp.Value, prec int, mode dfp.RoundingMode) (dfp.Value, error)
+
+func TestStats(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		f        statFunc
+		values   []dfp.Value
+		prec     int
+		mode     dfp.RoundingMode
+		expected string
+	}{
+		{Mean, values("1", "2", "4"), 2, dfp.RoundDown, "2.33"},
+		{Mean, values("1", "2", "4"), 2, dfp.RoundUp, "2.34"},
+		{Mean, values("1", "2", "4"), 30, dfp.RoundNearest, "2.3333333333333333"},
+		{Mean, values("1", "2"), 0, dfp.RoundNearest, "1"},
+		{Mean, values("1", "2"), 0, dfp.RoundHalfUp, "2"},
+		{Mean, values("1250", "1350"), -2, dfp.RoundHalfUp, "1300"},
+		{Mean, values("0.001"), 2, dfp.RoundDown, "0"},
+		{Mean, values("0.001"), 2, dfp.RoundUp, "0.01"},
+		{Mean, []dfp.Value{dfp.Max, dfp.Max}, 0, dfp.RoundDown, dfp.Max.String()},
+		{Variance, values("2", "4", "4", "4", "5", "5", "7", "9"), 2, dfp.RoundDown, "4"},
+		{StdDev, values("2", "4", "4", "4", "5", "5", "7", "9"), 2, dfp.RoundDown, "2"},
+		{SampleVariance, values("2", "4", "4", "4", "5", "5", "7", "9"), 4, dfp.RoundDown, "4.5714"},
+		{SampleStdDev, values("2", "4", "4", "4", "5", "5", "7", "9"), 4, dfp.RoundNearest, "2.1381"},
+		{SampleStdDev, values("2", "4", "4", "4", "5", "5", "7", "9"), 30, dfp.RoundDown, "2.138089935299395"},
+		{Variance, values("1", "2", "3"), 30, dfp.RoundDown, "0.66666666666666666"},
+		{Variance, values("1.5", "1.5"), 2, dfp.RoundDown, "0"},
+		{StdDev, values("0", "3"), 0, dfp.RoundDown, "1"},
+		{StdDev, values("0", "3"), 0, dfp.RoundNearest, "1"},
+		{StdDev, values("0", "3"), 0, dfp.RoundHalfUp, "2"},
+		{StdDev, values("0", "3"), 0, dfp.RoundUp, "2"},
+		{StdDev, values("0", "3"), 1, dfp.RoundUp, "1.5"},
+		{StdDev, values("0", "1e100"), 0, dfp.RoundDown, "5" + fmt.Sprintf("%099d", 0)},
+		{Median, values("3", "1", "2"), 2, dfp.RoundDown, "2"},
+		{Median, values("3", "1", "2", "4"), 2, dfp.RoundDown, "2.5"},
+		{Median, values("3", "1", "2", "4"), 0, dfp.RoundNearest, "2"},
+		{Median, values("7"), 0, dfp.RoundDown, "7"},
+	}
+	for i, test := range tests {
+		result, err := test.f(test.values, test.prec, test.mode)
+		if a.NoError(err, "%d", i) {
+			a.Equal(test.expected, result.String(), "%d", i)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	a := assert.New(t)
+	data := values("15", "20", "35", "40", "50")
+	tests := []struct {
+		p        string
+		expected string
+	}{
+		{"0%", "15"},
+		{"25%", "20"},
+		{"40%", "29"},
+		{"50%", "35"},
+		{"90%", "46"},
+		{"99.9%", "49.96"},
+		{"100%", "50"},
+	}
+	for _, test := range tests {
+		result, err := Percentile(data, dfp.MustParsePercent(test.p), 2, dfp.RoundDown)
+		if a.NoError(err, test.p) {
+			a.Equal(test.expected, result.String(), test.p)
+		}
+	}
+	_, err := Percentile(data, dfp.MustParsePercent("100.1%"), 2, dfp.RoundDown)
+	a.EqualError(err, "bad percentile: 100.1%")
+	a.Equal("15", data[0].String())
+}
+
+func TestWeightedMean(t *testing.T) {
+	a := assert.New(t)
+	result, err := WeightedMean(values("10", "20"), values("1", "3"), 2, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("17.5", result.String())
+	result, err = WeightedMean(values("1.25", "0.5", "3"), values("0.1", "0.2", "0"), 3, dfp.RoundHalfUp)
+	a.NoError(err)
+	a.Equal("0.75", result.String())
+	result, err = WeightedMean(values("1", "2"), values("1", "1"), 0, dfp.RoundHalfUp)
+	a.NoError(err)
+	a.Equal("2", result.String())
+
+	_, err = WeightedMean(values("1"), values("1", "2"), 2, dfp.RoundDown)
+	a.EqualError(err, "values and weights have different lengths: 1 and 2")
+	_, err = WeightedMean(values("1", "2"), values("0", "0"), 2, dfp.RoundDown)
+	a.EqualError(err, "zero total weight")
+}
+
+func TestSqrt(t *testing.T) {
+	a := assert.New(t)
+	a.Equal("1.5", Sqrt(big.NewRat(9, 4), 2, dfp.RoundDown).String())
+	a.Equal("1.41", Sqrt(big.NewRat(2, 1), 2, dfp.RoundDown).String())
+	a.Equal("1.42", Sqrt(big.NewRat(2, 1), 2, dfp.RoundUp).String())
+	a.Equal("1.414213562373095", Sqrt(big.NewRat(2, 1), 100, dfp.RoundNearest).String())
+	a.Equal("0", Sqrt(new(big.Rat), 2, dfp.RoundUp).String())
+}
+
+func TestErrors(t *testing.T) {
+	a := assert.New(t)
+	for _, f := range []statFunc{Mean, Variance, SampleVariance, StdDev, SampleStdDev, Median} {
+		_, err := f(nil, 2, dfp.RoundDown)
+		a.EqualError(err, "no values")
+	}
+	_, err := SampleVariance(values("1"), 2, dfp.RoundDown)
+	a.EqualError(err, "not enough values")
+	_, err = SampleStdDev(values("1"), 2, dfp.RoundDown)
+	a.EqualError(err, "not enough values")
+	_, err = WeightedMean(nil, nil, 2, dfp.RoundDown)
+	a.EqualError(err, "no values")
+}
+
+func TestRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 100; i++ {
+		data := make([]dfp.Value, rnd.Intn(100)+2)
+		exp := int32(rnd.Intn(40) - 20)
+		for j := range data {
+			data[j] = dfp.FromMantAndExp(uint64(rnd.Int63n(1<<56))>>uint(rnd.Intn(56)), exp+int32(rnd.Intn(10)))
+		}
+		// two-pass calculation.
+		n := big.NewRat(int64(len(data)), 1)
+		mean := new(big.Rat)
+		for _, v := range data {
+			mean.Add(mean, v.BigRat())
+		}
+		mean.Quo(mean, n)
+		variance := new(big.Rat)
+		for _, v := range data {
+			d := new(big.Rat).Sub(v.BigRat(), mean)
+			variance.Add(variance, d.Mul(d, d))
+		}
+		variance.Quo(variance, n)
+
+		for _, mode := range []dfp.RoundingMode{dfp.RoundDown, dfp.RoundNearest, dfp.RoundUp, dfp.RoundHalfUp} {
+			expected, _, _ := dfp.FromBigRat(mean, mode)
+			result, err := Mean(data, 127, mode)
+			a.NoError(err)
+			a.True(expected.Eq(result), "%v != %v", expected, result)
+			expected, _, _ = dfp.FromBigRat(variance, mode)
+			result, err = Variance(data, 127, mode)
+			a.NoError(err)
+			a.True(expected.Eq(result), "%v != %v", expected, result)
+		}
+
+		// s^2 <= variance < (s + ulp)^2, where ulp is 10^-prec, or greater, if s does not fit the mantissa.
+		prec := rnd.Intn(40) - 20
+		s, err := StdDev(data, prec, dfp.RoundDown)
+		a.NoError(err)
+		sr := s.BigRat()
+		e := -prec
+		for new(big.Rat).Quo(sr, pow10Rat(e)).Cmp(new(big.Rat).SetUint64(maxMantissa)) > 0 {
+			e++
+		}
+		upper := new(big.Rat).Add(sr, pow10Rat(e))
+		a.True(new(big.Rat).Mul(sr, sr).Cmp(variance) <= 0, "%v", s)
+		a.True(upper.Mul(upper, upper).Cmp(variance) > 0, "%v", s)
+	}
+}
+
+func pow10Rat(pow int) *big.Rat {
+	if pow < 0 {
+		return new(big.Rat).SetFrac(bigOne, pow10(-pow))
+	}
+	return new(big.Rat).SetInt(pow10(pow))
+}
+
+func BenchmarkVariance(b *testing.B) {
+	data := make([]dfp.Value, 1000)
+	for i := range data {
+		data[i] = dfp.FromMantAndExp(uint64(i*1234567), -int32(i%5))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Variance(data, 10, dfp.RoundHalfUp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package stats
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+// Stream calculates statistics over a stream of values in a single pass, without storing the values.
+// Unlike floating-point Welford's algorithm, it keeps exact sums of values and their squares,
+// so the variance does not suffer from cancellation.
+// The zero value is an empty stream ready to use.
+type Stream struct {
+	n          int64
+	sum, sumSq dfp.Accumulator
+	min, max   dfp.Value
+}
+
+// Add adds a value to the stream.
+func (s *Stream) Add(v dfp.Value) {
+	v = v.Normalized()
+	if s.n == 0 || v.Cmp(s.min) < 0 {
+		s.min = v
+	}
+	if s.n == 0 || v.Cmp(s.max) > 0 {
+		s.max = v
+	}
+	s.n++
+	s.sum.Add(v)
+	s.sumSq.AddMul(v, v)
+}
+
+// Count returns the number of values.
+func (s *Stream) Count() int64 {
+	return s.n
+}
+
+// Min returns the minimum value, or zero, if the stream is empty.
+func (s *Stream) Min() dfp.Value {
+	return s.min
+}
+
+// Max returns the maximum value, or zero, if the stream is empty.
+func (s *Stream) Max() dfp.Value {
+	return s.max
+}
+
+// Sum returns the sum of values rounded to prec decimal places.
+func (s *Stream) Sum(prec int, mode dfp.RoundingMode) dfp.Value {
+	sum, _, _ := s.sum.Result(prec, mode)
+	return sum
+}
+
+// Mean returns the arithmetic mean of values.
+func (s *Stream) Mean(prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	if s.n == 0 {
+		return 0, fmt.Errorf("no values")
+	}
+	mean := s.sum.BigRat()
+	return round(mean.Quo(mean, new(big.Rat).SetInt64(s.n)), prec, mode), nil
+}
+
+// Variance returns the population variance of values.
+func (s *Stream) Variance(prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	variance, err := s.variance(0)
+	if err != nil {
+		return 0, err
+	}
+	return round(variance, prec, mode), nil
+}
+
+// SampleVariance returns the sample variance of values. At least two values are required.
+func (s *Stream) SampleVariance(prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	variance, err := s.variance(1)
+	if err != nil {
+		return 0, err
+	}
+	return round(variance, prec, mode), nil
+}
+
+// StdDev returns the population standard deviation of values.
+func (s *Stream) StdDev(prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	variance, err := s.variance(0)
+	if err != nil {
+		return 0, err
+	}
+	return Sqrt(variance, prec, mode), nil
+}
+
+// SampleStdDev returns the sample standard deviation of values. At least two values are required.
+func (s *Stream) SampleStdDev(prec int, mode dfp.RoundingMode) (dfp.Value, error) {
+	variance, err := s.variance(1)
+	if err != nil {
+		return 0, err
+	}
+	return Sqrt(variance, prec, mode), nil
+}
+
+// variance returns (n * sumSq - sum^2) / (n * (n - ddof)).
+func (s *Stream) variance(ddof int64) (*big.Rat, error) {
+	if s.n <= ddof {
+		if s.n == 0 {
+			return nil, fmt.Errorf("no values")
+		}
+		return nil, fmt.Errorf("not enough values")
+	}
+	n := new(big.Rat).SetInt64(s.n)
+	sum := s.sum.BigRat()
+	result := s.sumSq.BigRat()
+	result.Mul(result, n).Sub(result, sum.Mul(sum, sum))
+	return result.Quo(result, n.Mul(n, new(big.Rat).SetInt64(s.n-ddof))), nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/avdva/numeric/dfp"
+)
+
+func TestStream(t *testing.T) {
+	a := assert.New(t)
+	var s Stream
+	a.Equal(int64(0), s.Count())
+	a.True(s.Min().IsZero())
+	a.True(s.Max().IsZero())
+	_, err := s.Mean(2, dfp.RoundDown)
+	a.EqualError(err, "no values")
+
+	for _, v := range values("2", "4.00", "4", "4", "5", "5", "7", "9") {
+		s.Add(v)
+	}
+	a.Equal(int64(8), s.Count())
+	a.Equal("2", s.Min().String())
+	a.Equal("9", s.Max().String())
+	a.Equal("40", s.Sum(2, dfp.RoundDown).String())
+	mean, err := s.Mean(2, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("5", mean.String())
+	variance, err := s.Variance(2, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("4", variance.String())
+	stdDev, err := s.StdDev(2, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("2", stdDev.String())
+	variance, err = s.SampleVariance(2, dfp.RoundHalfUp)
+	a.NoError(err)
+	a.Equal("4.57", variance.String())
+	stdDev, err = s.SampleStdDev(2, dfp.RoundUp)
+	a.NoError(err)
+	a.Equal("2.14", stdDev.String())
+}
+
+func TestStreamCancellation(t *testing.T) {
+	a := assert.New(t)
+	// a large mean with a tiny spread, where a naive float64 calculation loses all the digits.
+	var s Stream
+	for _, v := range values("100000000000.0001", "100000000000.0002", "100000000000.0003") {
+		s.Add(v)
+	}
+	variance, err := s.SampleVariance(10, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("0.00000001", variance.String())
+	stdDev, err := s.SampleStdDev(10, dfp.RoundDown)
+	a.NoError(err)
+	a.Equal("0.0001", stdDev.String())
+}
@@ -72,6 +72,21 @@ const (
 	modeFloor = iota
 	modeRound
 	modeCeil
+	modeHalfUp
+)
+
+// RoundingMode defines how the digits, that don't fit given precision, are discarded.
+type RoundingMode int
+
+const (
+	// RoundDown discards extra digits, the same way Floor does.
+	RoundDown RoundingMode = modeFloor
+	// RoundNearest rounds to the nearest value, the same way Round does. Halves are rounded down.
+	RoundNearest RoundingMode = modeRound
+	// RoundUp rounds to the nearest greater value, the same way Ceil does.
+	RoundUp RoundingMode = modeCeil
+	// RoundHalfUp rounds to the nearest value. Halves are rounded up.
+	RoundHalfUp RoundingMode = modeHalfUp
 )
 
 var (
@@ -267,6 +282,11 @@ func (v Value) MantUint64() uint64 {
 	return uint64(mant(v))
 }
 
+// Exp returns v's exponent as is.
+func (v Value) Exp() int32 {
+	return int32(exp(v))
+}
+
 // Eq returns true if both values represent the same number.
 func (v Value) Eq(other Value) bool {
 	if v == other {
@@ -282,6 +302,7 @@ func (v Value) IsZero() bool {
 
 // ToExp changes the mantissa of v so, that v = m * 10e'exp'.
 // As a result, mantissa can lose some digits in precision, become zero, or Max.
+// Use ToScaledInt64 to detect such cases.
 func (v Value) ToExp(exp int32) Value {
 	if exp > maxExponent {
 		return Max
@@ -406,6 +427,13 @@ func (v Value) Ceil(prec int) Value {
 	return adjustMantExp(round(m, e, prec, modeCeil))
 }
 
+// RoundTo rounds the value to prec decimal places using given rounding mode.
+// Note that prec can be negative.
+func (v Value) RoundTo(prec int, mode RoundingMode) Value {
+	m, e := split(v)
+	return adjustMantExp(round(m, e, prec, int(mode)))
+}
+
 // Add returns the sum of two values.
 // If the resulting mantissa overflows max mantissa, the least significant digits will be truncated.
 // If the result overflows Max, Max is returned.
@@ -524,7 +552,8 @@ func round(n number, e expType, prec, mode int) (number, expType) {
 	if toCut <= 0 {
 		return n, e
 	}
-	if toCut > dd {
+	allCut := toCut > dd
+	if allCut {
 		toCut = dd
 	}
 	p := pow10(toCut)
@@ -532,8 +561,9 @@ func round(n number, e expType, prec, mode int) (number, expType) {
 	n /= p
 	ei += toCut
 	switch mode {
-	case modeRound:
-		if r > p/2 {
+	case modeRound, modeHalfUp:
+		// if all the digits were cut, the value is less than a half.
+		if !allCut && roundUp(r, p, mode) {
 			n++
 		}
 	case modeCeil:
@@ -549,6 +579,20 @@ func round(n number, e expType, prec, mode int) (number, expType) {
 	return n, expType(ei)
 }
 
+// roundUp returns true, if a quotient with remainder r and divisor d should be incremented.
+func roundUp(r, d uint64, mode int) bool {
+	switch mode {
+	case modeRound:
+		return r > d-r
+	case modeHalfUp:
+		return r != 0 && r >= d-r
+	case modeCeil:
+		return r != 0
+	default:
+		return false
+	}
+}
+
 // Div calculates a/b. If b == 0, Div panics.
 // First, it tries to perform integer division, and if the remainder is zero, returns the result.
 // Otherwise, it returns the result of a float64 division.
@@ -563,6 +607,83 @@ func (v Value) Div(other Value) Value {
 	return float64Div(v, other)
 }
 
+// Quo calculates a/b rounded to prec decimal places using given rounding mode. If b == 0, Quo panics.
+// Unlike Div, it never falls back to float64 division, so all the digits of the result are exact.
+// If the quotient has more digits, than the mantissa can hold, the least significant digits are rounded.
+// Note that prec can be negative.
+func (v Value) Quo(other Value, prec int, mode RoundingMode) Value {
+	m1, e1 := split(v.Normalized())
+	m2, e2 := split(other.Normalized())
+	if m2 == 0 {
+		panic("division by zero")
+	}
+	if m1 == 0 {
+		return zero
+	}
+	return adjustMantExp(quo(m1, e1, m2, e2, prec, int(mode)))
+}
+
+// quo calculates m1*10^e1 / m2*10^e2 so, that the result has at most prec decimal places,
+// and its mantissa has no more than digitsInMaxMantissa-1 digits.
+func quo(m1 number, e1 expType, m2 number, e2 expType, prec, mode int) (number, expType) {
+	// m1*10^e1 / m2*10^e2 = (m1*10^shift / m2) * 10^-prec, where shift = e1-e2+prec.
+	shift := int(e1) - int(e2) + prec
+	if qDigits := quoDigits(m1, m2) + shift; qDigits > digitsInMaxMantissa-1 {
+		cut := qDigits - (digitsInMaxMantissa - 1)
+		shift -= cut
+		prec -= cut
+	}
+	if -prec < minExponent {
+		shift -= minExponent + prec
+		prec = -minExponent
+	}
+	var q, r, d uint64
+	if shift >= 0 {
+		// the quotient fits uint64, so it is safe to perform a 128-bit multiplication and division.
+		hi, lo := bits.Mul64(m1, pow10(min(shift, len(decimalFactorTable)-1)))
+		if rest := shift - (len(decimalFactorTable) - 1); rest > 0 {
+			hi, lo = mul128(hi, lo, pow10(rest))
+		}
+		d = m2
+		q, r = bits.Div64(hi, lo, d)
+	} else {
+		hi, lo := bits.Mul64(m2, pow10(-shift))
+		if hi > 0 || lo == 0 { // the divisor doesn't fit uint64, so the quotient is zero.
+			if mode == modeCeil {
+				return 1, expType(-prec)
+			}
+			return 0, 0
+		}
+		d = lo
+		q, r = m1/d, m1%d
+	}
+	if roundUp(r, d, mode) {
+		q++
+	}
+	return q, expType(-prec)
+}
+
+// quoDigits returns the number of decimal digits in the integer part of m1/m2,
+// if m1 and m2 had the same number of digits.
+func quoDigits(m1, m2 number) int {
+	d1, d2 := decimalDigits(m1), decimalDigits(m2)
+	if d1 < d2 {
+		m1 *= pow10(d2 - d1)
+	} else {
+		m2 *= pow10(d1 - d2)
+	}
+	if m1 < m2 {
+		return d1 - d2
+	}
+	return d1 - d2 + 1
+}
+
+// mul128 multiplies a 128-bit number by a 64-bit number. It does not check for overflow.
+func mul128(hi, lo, m uint64) (rhi, rlo uint64) {
+	carry, rlo := bits.Mul64(lo, m)
+	return hi*m + carry, rlo
+}
+
 func float64Div(a, b Value) Value {
 	m1, e1 := split(a)
 	m2, e2 := split(b)
@@ -657,6 +778,13 @@ func log10(a uint64) int {
 	return decimalDigits(a) - 1
 }
 
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func abs(val int) int {
 	mask := val >> (unsafe.Sizeof(0)*8 - 1)
 	return (val + mask) ^ mask
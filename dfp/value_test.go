@@ -585,6 +585,9 @@ func TestMantUint64(t *testing.T) {
 	for i, item := range tests {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
 			a.Equal(item.mant, item.v.ToExp(item.exp).MantUint64())
+			if item.mant != 0 && item.mant != maxMantissa {
+				a.Equal(item.exp, item.v.ToExp(item.exp).Exp())
+			}
 		})
 	}
 }
@@ -992,6 +995,13 @@ func TestRound(t *testing.T) {
 			MustFromString("000.000013"),
 			6,
 		},
+		{
+			MustFromString("0.00006"),
+			MustFromString("0"),
+			MustFromString("0"),
+			MustFromString(".01"),
+			2,
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
@@ -1002,6 +1012,70 @@ func TestRound(t *testing.T) {
 	}
 }
 
+func TestRoundTo(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, expected Value
+		prec        int
+		mode        RoundingMode
+	}{
+		{zero, zero, 2, RoundHalfUp},
+		{MustFromString("0.125"), MustFromString("0.12"), 2, RoundDown},
+		{MustFromString("0.125"), MustFromString("0.12"), 2, RoundNearest},
+		{MustFromString("0.125"), MustFromString("0.13"), 2, RoundHalfUp},
+		{MustFromString("0.125"), MustFromString("0.13"), 2, RoundUp},
+		{MustFromString("0.1251"), MustFromString("0.13"), 2, RoundNearest},
+		{MustFromString("0.1249"), MustFromString("0.12"), 2, RoundHalfUp},
+		{MustFromString("1.5"), MustFromString("2"), 0, RoundHalfUp},
+		{MustFromString("1.5"), MustFromString("1"), 0, RoundNearest},
+		{MustFromString("0.00006"), zero, 2, RoundNearest},
+		{MustFromString("0.00006"), zero, 2, RoundHalfUp},
+		{MustFromString("0.00006"), MustFromString("0.01"), 2, RoundUp},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			a.Equal(test.expected, test.a.RoundTo(test.prec, test.mode), "%s (%d)", test.a, test.prec)
+		})
+	}
+}
+
+func TestQuo(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		a, b, expected Value
+		prec           int
+		mode           RoundingMode
+	}{
+		{zero, MustFromString("3"), zero, 2, RoundUp},
+		{MustFromString("10"), MustFromString("4"), MustFromString("2.5"), 2, RoundDown},
+		{MustFromString("10"), MustFromString("4"), MustFromString("2"), 0, RoundNearest},
+		{MustFromString("10"), MustFromString("4"), MustFromString("3"), 0, RoundHalfUp},
+		{MustFromString("10"), MustFromString("3"), MustFromString("3.33"), 2, RoundNearest},
+		{MustFromString("10"), MustFromString("3"), MustFromString("3.34"), 2, RoundUp},
+		{MustFromString("20"), MustFromString("3"), MustFromString("6.67"), 2, RoundNearest},
+		{MustFromString("20"), MustFromString("3"), MustFromString("6.66"), 2, RoundDown},
+		{MustFromString("1"), MustFromString("3"), MustFromString("0.3333333333333333"), 100, RoundDown},
+		{MustFromString("1"), MustFromString("7"), MustFromString("0.1428571428571429"), 100, RoundHalfUp},
+		{
+			MustFromString("1"), MustFromString("1.234567890123456"),
+			MustFromString("0.8100000072900006"), 100, RoundHalfUp,
+		},
+		{MustFromString("1"), MustFromString("3e20"), zero, 2, RoundHalfUp},
+		{MustFromString("1"), MustFromString("3e20"), MustFromString("0.01"), 2, RoundUp},
+		{MustFromString("123456"), MustFromString("7"), MustFromString("17600"), -2, RoundDown},
+		{MustFromString("1e-120"), MustFromString("3e10"), zero, 200, RoundDown},
+		{MustFromString("1e-100"), MustFromString("3e10"), MustFromString("3333333333333333e-126"), 200, RoundDown},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			a.Equal(test.expected.Normalized(), test.a.Quo(test.b, test.prec, test.mode).Normalized(), "%s / %s", test.a, test.b)
+		})
+	}
+	a.Panics(func() {
+		MustFromString("1").Quo(zero, 2, RoundDown)
+	})
+}
+
 func TestDecimalDigits(t *testing.T) {
 	a := assert.New(t)
 	tests := []uint64{0, 1, 9, 10, 11, 100, 1000, 1e10, maxMantissa, math.MaxUint64}
@@ -0,0 +1,270 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"math/bits"
+)
+
+// Vector is a sequence of values, which share an exponent.
+// Vector operations are integer loops over mantissas, and do not align exponents for every element.
+type Vector struct {
+	mants []uint64
+	exp   int32
+}
+
+// NewVector returns a vector for given values. The exponent is chosen so, that all the values are represented
+// precisely, if possible. Otherwise, the exponent is the smallest one, that allows to represent the greatest value,
+// the values are rounded according to the mode, and the result is returned along with ErrInexact.
+func NewVector(values []Value, mode RoundingMode) (Vector, error) {
+	exp, maxTop, found := int(maxExponent), int(minExponent), false
+	for _, v := range values {
+		m, e := split(v.Normalized())
+		if m == 0 {
+			continue
+		}
+		found = true
+		if int(e) < exp {
+			exp = int(e)
+		}
+		if top := int(e) + decimalDigits(m) - 1; top > maxTop {
+			maxTop = top
+		}
+	}
+	if !found {
+		return NewVectorExp(values, 0, mode)
+	}
+	if lowest := maxTop - (digitsInMaxMantissa - 1); exp < lowest {
+		exp = lowest
+	}
+	if exp < minExponent {
+		exp = minExponent
+	}
+	vec, err := NewVectorExp(values, int32(exp), mode)
+	if err == ErrOverflow {
+		// the greatest value has digitsInMaxMantissa digits, but exceeds max mantissa.
+		return NewVectorExp(values, int32(exp+1), mode)
+	}
+	return vec, err
+}
+
+// NewVectorExp returns a vector for given values and exponent.
+// If some values have more decimal places, they are rounded according to the mode,
+// and the result is returned along with ErrInexact.
+// If a value can not be represented with given exponent, ErrOverflow is returned.
+func NewVectorExp(values []Value, exp int32, mode RoundingMode) (Vector, error) {
+	vec := Vector{mants: make([]uint64, len(values)), exp: exp}
+	var err error
+	for i, v := range values {
+		m, e := split(v.Normalized())
+		if m == 0 {
+			continue
+		}
+		if e >= exp {
+			p := pow10(int(e - exp))
+			if p == 0 || m > maxMantissa/p {
+				return Vector{}, ErrOverflow
+			}
+			vec.mants[i] = m * p
+			continue
+		}
+		q, inexact := divRound128(0, m, int(exp-e), int(mode))
+		if inexact {
+			err = ErrInexact
+		}
+		if q > maxMantissa {
+			return Vector{}, ErrOverflow
+		}
+		vec.mants[i] = q
+	}
+	return vec, err
+}
+
+// Len returns the number of values.
+func (v Vector) Len() int {
+	return len(v.mants)
+}
+
+// Exp returns the shared exponent.
+func (v Vector) Exp() int32 {
+	return v.exp
+}
+
+// At returns i-th value.
+func (v Vector) At(i int) Value {
+	return FromMantAndExp(v.mants[i], v.exp)
+}
+
+// Values returns all the values.
+func (v Vector) Values() []Value {
+	values := make([]Value, len(v.mants))
+	for i, m := range v.mants {
+		values[i] = FromMantAndExp(m, v.exp)
+	}
+	return values
+}
+
+// Sum returns the sum of all the values.
+// The sum is calculated precisely, and the least significant digits of the result are truncated, if needed.
+func (v Vector) Sum() Value {
+	var acc accumulator192
+	for _, m := range v.mants {
+		acc.add(0, m)
+	}
+	return acc.value(int(v.exp))
+}
+
+// Dot returns the dot product of two vectors. It panics, if the lengths are different.
+// The product is calculated precisely, and the least significant digits of the result are truncated, if needed.
+func (v Vector) Dot(other Vector) Value {
+	if len(v.mants) != len(other.mants) {
+		panic("vectors have different lengths")
+	}
+	var acc accumulator192
+	for i, m := range v.mants {
+		acc.add(bits.Mul64(m, other.mants[i]))
+	}
+	return acc.value(int(v.exp) + int(other.exp))
+}
+
+// Scale returns a vector, which values are multiplied by the factor.
+// If the products have too many digits, they are rounded according to the mode,
+// and the result is returned along with ErrInexact.
+// If the products can not be represented, ErrOverflow is returned.
+func (v Vector) Scale(factor Value, mode RoundingMode) (Vector, error) {
+	fm, fe := split(factor.Normalized())
+	var maxHi, maxLo uint64
+	for _, m := range v.mants {
+		if hi, lo := bits.Mul64(m, fm); hi > maxHi || hi == maxHi && lo > maxLo {
+			maxHi, maxLo = hi, lo
+		}
+	}
+	// cut the digits, so that the greatest product fits max mantissa, and the exponent is in range.
+	toCut := 0
+	for maxHi != 0 || maxLo > maxMantissa {
+		maxHi, maxLo = maxHi/10, div128(maxHi%10, maxLo, 10)
+		toCut++
+	}
+	exp := int(v.exp) + int(fe) + toCut
+	if exp < minExponent {
+		toCut += minExponent - exp
+		exp = minExponent
+	}
+	result := Vector{mants: make([]uint64, len(v.mants))}
+	var err error
+	for i := 0; i < len(v.mants); i++ {
+		hi, lo := bits.Mul64(v.mants[i], fm)
+		q, inexact := divRound128(hi, lo, toCut, int(mode))
+		if q > maxMantissa {
+			// rounding up added a digit, so start again with one more digit to cut.
+			toCut++
+			exp++
+			i, err = -1, nil
+			continue
+		}
+		if inexact {
+			err = ErrInexact
+		}
+		result.mants[i] = q
+	}
+	for ; exp > maxExponent; exp-- {
+		for i, m := range result.mants {
+			if m > maxMantissa/10 {
+				return Vector{}, ErrOverflow
+			}
+			result.mants[i] = m * 10
+		}
+	}
+	result.exp = int32(exp)
+	return result, err
+}
+
+// Min returns the minimum value, or zero for an empty vector.
+func (v Vector) Min() Value {
+	if len(v.mants) == 0 {
+		return zero
+	}
+	min := v.mants[0]
+	for _, m := range v.mants[1:] {
+		if m < min {
+			min = m
+		}
+	}
+	return FromMantAndExp(min, v.exp)
+}
+
+// Max returns the maximum value, or zero for an empty vector.
+func (v Vector) Max() Value {
+	var max uint64
+	for _, m := range v.mants {
+		if m > max {
+			max = m
+		}
+	}
+	return FromMantAndExp(max, v.exp)
+}
+
+// divRound128 returns hi:lo / 10^digits rounded according to the mode, and true, if the remainder is not zero.
+// hi:lo must be less, than 10^34, and the quotient must fit uint64.
+func divRound128(hi, lo uint64, digits, mode int) (q uint64, inexact bool) {
+	if digits == 0 {
+		return lo, false
+	}
+	nonZero := hi != 0 || lo != 0
+	if digits > 36 {
+		// the divisor is more, than twice the number.
+		if nonZero && mode == modeCeil {
+			return 1, true
+		}
+		return 0, nonZero
+	}
+	// divide by p1 first, and then by p2, keeping track of whether the first remainder is zero,
+	// so that both divisors and doubled p2 fit uint64.
+	p1, p2 := uint64(1), pow10(digits)
+	if digits > 18 {
+		p1, p2 = pow10(digits-18), pow10(18)
+	}
+	var r1 uint64
+	if p1 > 1 {
+		var qlo uint64
+		qlo, r1 = bits.Div64(hi%p1, lo, p1)
+		hi, lo = hi/p1, qlo
+	}
+	q, r2 := bits.Div64(hi, lo, p2)
+	sticky := uint64(0)
+	if r1 != 0 {
+		sticky = 1
+	}
+	if roundUp(2*r2+sticky, 2*p2, mode) {
+		q++
+	}
+	return q, r1 != 0 || r2 != 0
+}
+
+func div128(hi, lo, d uint64) uint64 {
+	q, _ := bits.Div64(hi, lo, d)
+	return q
+}
+
+// accumulator192 is a 192-bit unsigned integer.
+type accumulator192 [3]uint64
+
+func (a *accumulator192) add(hi, lo uint64) {
+	var carry uint64
+	a[0], carry = bits.Add64(a[0], lo, 0)
+	a[1], carry = bits.Add64(a[1], hi, carry)
+	a[2] += carry
+}
+
+// value returns a value for the accumulated mantissa and given exponent.
+func (a *accumulator192) value(exp int) Value {
+	w := *a
+	for w[2] != 0 || w[1] != 0 {
+		var r uint64
+		w[2], r = bits.Div64(0, w[2], 10)
+		w[1], r = bits.Div64(r, w[1], 10)
+		w[0], _ = bits.Div64(r, w[0], 10)
+		exp++
+	}
+	return FromMantAndExp(w[0], int32(exp))
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 Aleksandr Demakin. All rights reserved.
+
+package dfp
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func valuesFromStrings(strs ...string) []Value {
+	values := make([]Value, len(strs))
+	for i, s := range strs {
+		values[i] = MustFromString(s)
+	}
+	return values
+}
+
+func valuesToStrings(values []Value) []string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.String()
+	}
+	return strs
+}
+
+func TestNewVector(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		values   []string
+		mode     RoundingMode
+		exp      int32
+		expected []string
+		err      error
+	}{
+		{nil, RoundDown, 0, []string{}, nil},
+		{[]string{"0", "0"}, RoundDown, 0, []string{"0", "0"}, nil},
+		{[]string{"1.25", "1.3", "100"}, RoundDown, -2, []string{"1.25", "1.3", "100"}, nil},
+		{[]string{"1e20", "0.001"}, RoundDown, 4, []string{"100000000000000000000", "0"}, ErrInexact},
+		{[]string{"1e20", "0.001"}, RoundUp, 4, []string{"100000000000000000000", "10000"}, ErrInexact},
+		{[]string{"90000000000000000", "0.5"}, RoundHalfUp, 1, []string{"90000000000000000", "0"}, ErrInexact},
+		{[]string{"1e-127", "1e-100"}, RoundDown, -116, []string{"0", "0." + fmt.Sprintf("%0100d", 1)}, ErrInexact},
+		{[]string{"1e-127", "1e-112"}, RoundDown, -127, []string{"0." + fmt.Sprintf("%0127d", 1), "0." + fmt.Sprintf("%0112d", 1)}, nil},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			vec, err := NewVector(valuesFromStrings(test.values...), test.mode)
+			a.Equal(test.err, err)
+			a.Equal(test.exp, vec.Exp())
+			a.Equal(len(test.values), vec.Len())
+			a.Equal(test.expected, valuesToStrings(vec.Values()))
+			for j, expected := range test.expected {
+				a.Equal(expected, vec.At(j).String())
+			}
+		})
+	}
+}
+
+func TestNewVectorExp(t *testing.T) {
+	a := assert.New(t)
+	values := valuesFromStrings("1.25", "1.24", "3")
+	tests := []struct {
+		exp      int32
+		mode     RoundingMode
+		expected []string
+		err      error
+	}{
+		{-2, RoundDown, []string{"1.25", "1.24", "3"}, nil},
+		{-1, RoundDown, []string{"1.2", "1.2", "3"}, ErrInexact},
+		{-1, RoundNearest, []string{"1.2", "1.2", "3"}, ErrInexact},
+		{-1, RoundHalfUp, []string{"1.3", "1.2", "3"}, ErrInexact},
+		{-1, RoundUp, []string{"1.3", "1.3", "3"}, ErrInexact},
+		{1, RoundUp, []string{"10", "10", "10"}, ErrInexact},
+		{100, RoundNearest, []string{"0", "0", "0"}, ErrInexact},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			vec, err := NewVectorExp(values, test.exp, test.mode)
+			a.Equal(test.err, err)
+			a.Equal(test.expected, valuesToStrings(vec.Values()))
+		})
+	}
+	_, err := NewVectorExp(valuesFromStrings("1e20"), -5, RoundDown)
+	a.Equal(ErrOverflow, err)
+}
+
+func TestVectorOps(t *testing.T) {
+	a := assert.New(t)
+	vec, err := NewVector(valuesFromStrings("1.5", "2.25", "0.75"), RoundDown)
+	a.NoError(err)
+	a.Equal("4.5", vec.Sum().String())
+	a.Equal("0.75", vec.Min().String())
+	a.Equal("2.25", vec.Max().String())
+	a.Equal("7.875", vec.Dot(vec).String())
+
+	scaled, err := vec.Scale(MustFromString("2"), RoundDown)
+	a.NoError(err)
+	a.Equal([]string{"3", "4.5", "1.5"}, valuesToStrings(scaled.Values()))
+	scaled, err = vec.Scale(MustFromString("0.001"), RoundDown)
+	a.NoError(err)
+	a.Equal([]string{"0.0015", "0.00225", "0.00075"}, valuesToStrings(scaled.Values()))
+
+	thirds, _ := NewVector(valuesFromStrings("1", "2"), RoundDown)
+	scaled, err = thirds.Scale(MustFromString("0.33333333333333333"), RoundDown)
+	a.NoError(err)
+	a.Equal([]string{"0.33333333333333333", "0.66666666666666666"}, valuesToStrings(scaled.Values()))
+	scaled, err = thirds.Scale(MustFromString("66666666666666667"), RoundHalfUp)
+	a.Equal(ErrInexact, err)
+	a.Equal([]string{"66666666666666670", "133333333333333330"}, valuesToStrings(scaled.Values()))
+
+	maxVec, _ := NewVector([]Value{Max}, RoundDown)
+	_, err = maxVec.Scale(MustFromString("10"), RoundDown)
+	a.Equal(ErrOverflow, err)
+	minVec, _ := NewVector([]Value{Min}, RoundDown)
+	scaled, err = minVec.Scale(MustFromString("0.1"), RoundDown)
+	a.Equal(ErrInexact, err)
+	a.Equal([]string{"0"}, valuesToStrings(scaled.Values()))
+	scaled, err = minVec.Scale(MustFromString("0.1"), RoundUp)
+	a.Equal(ErrInexact, err)
+	a.True(Min.Eq(scaled.At(0)))
+
+	var empty Vector
+	a.Equal(zero, empty.Sum())
+	a.Equal(zero, empty.Min())
+	a.Equal(zero, empty.Max())
+	a.Panics(func() { vec.Dot(empty) })
+}
+
+func TestVectorRandom(t *testing.T) {
+	a := assert.New(t)
+	rnd := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 100; i++ {
+		values := make([]Value, rnd.Intn(1000)+1)
+		// 7-digit mantissas with exponents in a range of 10 fit max mantissa with the smallest exponent.
+		exp := rnd.Intn(20) - 10
+		for j := range values {
+			values[j] = fromMantAndExp(uint64(rnd.Int63n(1e7))>>uint(rnd.Intn(24)), expType(exp+rnd.Intn(10)))
+		}
+		vec, err := NewVector(values, RoundDown)
+		if !a.NoError(err) {
+			break
+		}
+		sum, dot := new(big.Rat), new(big.Rat)
+		for j, v := range values {
+			a.True(v.Eq(vec.At(j)))
+			sum.Add(sum, v.BigRat())
+			dot.Add(dot, new(big.Rat).Mul(v.BigRat(), v.BigRat()))
+		}
+		expected, _, _ := FromBigRat(sum, RoundDown)
+		a.True(expected.Eq(vec.Sum()), "%v != %v", expected, vec.Sum())
+		expected, _, _ = FromBigRat(dot, RoundDown)
+		a.True(expected.Eq(vec.Dot(vec)), "%v != %v", expected, vec.Dot(vec))
+
+		factor := fromMantAndExp(uint64(rnd.Int63n(maxMantissa))>>uint(rnd.Intn(56)), expType(rnd.Intn(20)-10))
+		for _, mode := range []RoundingMode{RoundDown, RoundNearest, RoundUp, RoundHalfUp} {
+			scaled, err := vec.Scale(factor, mode)
+			if err != nil && !a.Equal(ErrInexact, err) {
+				break
+			}
+			ulp := big.NewRat(1, 1)
+			if scaled.Exp() < 0 {
+				ulp.SetFrac(big.NewInt(1), bigPow10(int(-scaled.Exp())))
+			} else {
+				ulp.SetInt(bigPow10(int(scaled.Exp())))
+			}
+			for j, v := range values {
+				exact := new(big.Rat).Mul(v.BigRat(), factor.BigRat())
+				diff := new(big.Rat).Sub(scaled.At(j).BigRat(), exact)
+				a.True(new(big.Rat).Abs(diff).Cmp(ulp) < 0, "%v * %v = %v", v, factor, scaled.At(j))
+				switch mode {
+				case RoundDown:
+					a.True(diff.Sign() <= 0)
+				case RoundUp:
+					a.True(diff.Sign() >= 0)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkVectorSum(b *testing.B) {
+	values := make([]Value, 10000)
+	for i := range values {
+		values[i] = FromMantAndExp(uint64(i*1234567), -int32(i%5))
+	}
+	vec, _ := NewVector(values, RoundDown)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vec.Sum()
+	}
+}
+
+func BenchmarkValueSum(b *testing.B) {
+	values := make([]Value, 10000)
+	for i := range values {
+		values[i] = FromMantAndExp(uint64(i*1234567), -int32(i%5))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum Value
+		for _, v := range values {
+			sum = sum.Add(v)
+		}
+	}
+}